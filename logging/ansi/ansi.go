@@ -1,6 +1,9 @@
 package ansi
 
-import "strings"
+import (
+	"strings"
+	"unicode/utf8"
+)
 
 type EscapeCode int
 
@@ -98,15 +101,35 @@ var strs = map[EscapeCode]string{
 	BgBrightWhite:   "\033[107m",
 }
 
+// Combine merges codes into a single SGR sequence, ex. Combine(FgRed, Bold) returns
+// "\033[31;1m" instead of "\033[31m\033[1m"
+func Combine(codes ...EscapeCode) string {
+	if len(codes) == 0 {
+		return ""
+	}
+
+	params := make([]string, len(codes))
+	for i, ec := range codes {
+		params[i] = strings.TrimSuffix(strings.TrimPrefix(strs[ec], "\033["), "m")
+	}
+
+	return "\033[" + strings.Join(params, ";") + "m"
+}
+
 type EscapeMode int
 
 const (
-	EscapeMode_Enable = iota
-	EscapeMode_Disable
+	EscapeMode_Disable EscapeMode = iota
+	EscapeMode_Enable
 )
 
+// AnsiStringBuilder builds strings containing escape codes. The zero value uses
+// [EscapeMode_Disable] and drops escape codes, use [NewAnsiStringBuilder] or
+// [AnsiStringBuilder.SetEscapeMode] to emit them.
+// The contents are kept in a byte slice rather than a [strings.Builder], so that
+// [AnsiStringBuilder.Reset] keeps the allocated buffer for reuse
 type AnsiStringBuilder struct {
-	str        strings.Builder
+	buf        []byte
 	escapeMode EscapeMode
 }
 
@@ -118,8 +141,24 @@ func (builder *AnsiStringBuilder) SetEscapeMode(mode EscapeMode) {
 	builder.escapeMode = mode
 }
 
+func (builder *AnsiStringBuilder) EscapeMode() EscapeMode {
+	return builder.escapeMode
+}
+
 func (builder *AnsiStringBuilder) WriteString(s string) (int, error) {
-	return builder.str.WriteString(s)
+	builder.buf = append(builder.buf, s...)
+	return len(s), nil
+}
+
+func (builder *AnsiStringBuilder) WriteRune(r rune) (int, error) {
+	n := len(builder.buf)
+	builder.buf = utf8.AppendRune(builder.buf, r)
+	return len(builder.buf) - n, nil
+}
+
+func (builder *AnsiStringBuilder) WriteByte(b byte) error {
+	builder.buf = append(builder.buf, b)
+	return nil
 }
 
 func (builder *AnsiStringBuilder) WriteEscapeCode(ec EscapeCode) (int, error) {
@@ -127,7 +166,16 @@ func (builder *AnsiStringBuilder) WriteEscapeCode(ec EscapeCode) (int, error) {
 		return 0, nil
 	}
 
-	return builder.str.WriteString(strs[ec])
+	return builder.WriteString(strs[ec])
+}
+
+// WriteCombined writes codes as a single sequence, see [Combine]
+func (builder *AnsiStringBuilder) WriteCombined(codes ...EscapeCode) (int, error) {
+	if builder.escapeMode == EscapeMode_Disable {
+		return 0, nil
+	}
+
+	return builder.WriteString(Combine(codes...))
 }
 
 func (builder *AnsiStringBuilder) Write(ss ...any) (int, error) {
@@ -155,6 +203,39 @@ func (builder *AnsiStringBuilder) Write(ss ...any) (int, error) {
 	return n, nil
 }
 
+// WriteLine is like [AnsiStringBuilder.Write] followed by a newline
+func (builder *AnsiStringBuilder) WriteLine(ss ...any) (int, error) {
+	n, err := builder.Write(ss...)
+	if err != nil {
+		return n, err
+	}
+
+	nn, err := builder.WriteString("\n")
+	return n + nn, err
+}
+
+// Reset clears the contents of the builder, keeping the escape mode and the allocated
+// buffer
+func (builder *AnsiStringBuilder) Reset() {
+	builder.buf = builder.buf[:0]
+}
+
+// Len returns the number of bytes written so far, including escape codes
+func (builder *AnsiStringBuilder) Len() int {
+	return len(builder.buf)
+}
+
+// Cap returns the capacity of the buffer, which is kept across [AnsiStringBuilder.Reset]
+func (builder *AnsiStringBuilder) Cap() int {
+	return cap(builder.buf)
+}
+
+// Bytes returns the contents of the builder, which are only valid until the next write or
+// [AnsiStringBuilder.Reset]
+func (builder *AnsiStringBuilder) Bytes() []byte {
+	return builder.buf
+}
+
 func (builder *AnsiStringBuilder) String() string {
-	return builder.str.String()
+	return string(builder.buf)
 }
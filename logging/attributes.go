@@ -0,0 +1,164 @@
+package logging
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+func Attr(key string, value any) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+func String(key string, value string) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+func Int(key string, value int) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+func Int64(key string, value int64) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+func Bool(key string, value bool) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+func Float64(key string, value float64) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+func Duration(key string, value time.Duration) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+func Time(key string, value time.Time) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Err creates an attribute with err under the conventional "error" key
+func Err(err error) Attribute {
+	return Attribute{Key: "error", Value: err}
+}
+
+// Group creates an attribute whose value is the nested attributes parsed from args
+func Group(key string, args ...any) Attribute {
+	return Attribute{Key: key, Value: argsToAttrs(args)}
+}
+
+// String formats the attribute as key=value, with groups formatted as key={key=value ...}
+func (attr Attribute) String() string {
+	switch v := resolveValue(attr.Value).(type) {
+	case []Attribute:
+		parts := make([]string, len(v))
+		for i, a := range v {
+			parts[i] = a.String()
+		}
+
+		return attr.Key + "={" + strings.Join(parts, " ") + "}"
+	default:
+		return fmt.Sprintf("%s=%v", attr.Key, v)
+	}
+}
+
+// AttrValue returns the value of the first attribute matching key. Attributes nested in
+// groups are addressed by joining the keys with dots, such as "http.status". Lazy values and
+// [LogValuer] values along the way are resolved to find the groups they return, the matching
+// value itself is returned as logged.
+func (record Record) AttrValue(key string) (any, bool) {
+	attrs := record.Attributes
+	parts := strings.Split(key, ".")
+
+	for i, part := range parts {
+		j := -1
+		for k, attr := range attrs {
+			if attr.Key == part {
+				j = k
+				break
+			}
+		}
+
+		if j == -1 {
+			return nil, false
+		}
+
+		if i == len(parts)-1 {
+			return attrs[j].Value, true
+		}
+
+		group, ok := resolveValue(attrs[j].Value).([]Attribute)
+		if !ok {
+			return nil, false
+		}
+
+		attrs = group
+	}
+
+	return nil, false
+}
+
+// LazyValue is an attribute value that is only computed when a handler emits the record
+type LazyValue func() any
+
+// LogValuer is implemented by attribute values that control their own logged
+// representation. LogValue is called when a handler emits the record, so a type can, for
+// example, log only its ID and omit sensitive fields.
+type LogValuer interface {
+	LogValue() any
+}
+
+// Maximum number of lazy values and LogValue calls resolved for a single value, so that a
+// value returning itself does not loop forever. Values that exceed it are logged as
+// "!MAXDEPTH".
+const maxResolveDepth = 32
+
+// resolveValue evaluates lazy values and [logging.LogValuer] values, recursing into groups
+func resolveValue(value any) any {
+	for depth := 0; depth < maxResolveDepth; depth++ {
+		switch v := value.(type) {
+		case LazyValue:
+			value = v()
+		case LogValuer:
+			value = v.LogValue()
+		case []Attribute:
+			return resolveAttrs(v)
+		default:
+			return v
+		}
+	}
+
+	return "!MAXDEPTH"
+}
+
+// resolveAttrs returns a copy of attrs with every lazy value and [logging.LogValuer] evaluated
+func resolveAttrs(attrs []Attribute) []Attribute {
+	resolved := make([]Attribute, len(attrs))
+	for i, attr := range attrs {
+		resolved[i] = Attribute{Key: attr.Key, Value: resolveValue(attr.Value)}
+	}
+
+	return resolved
+}
+
+// Bytes is a byte count that the pretty handler renders in binary units, ex. 1.0 MiB. It is
+// marshaled to JSON as the raw integer.
+type Bytes int64
+
+func (b Bytes) String() string {
+	const unit = 1024
+
+	n := int64(b)
+	if n < unit && n > -unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	value, exp := float64(n), 0
+	for value >= unit*unit || value <= -unit*unit {
+		value /= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", value/unit, "KMGTPE"[exp])
+}
@@ -0,0 +1,75 @@
+package logging
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTypedAttributes(t *testing.T) {
+	now := time.Now()
+	err := errors.New("failed")
+
+	tests := []struct {
+		got  Attribute
+		want Attribute
+	}{
+		{String("k", "v"), Attribute{Key: "k", Value: "v"}},
+		{Int("k", 1), Attribute{Key: "k", Value: 1}},
+		{Int64("k", 1), Attribute{Key: "k", Value: int64(1)}},
+		{Bool("k", true), Attribute{Key: "k", Value: true}},
+		{Float64("k", 1.5), Attribute{Key: "k", Value: 1.5}},
+		{Duration("k", time.Second), Attribute{Key: "k", Value: time.Second}},
+		{Time("k", now), Attribute{Key: "k", Value: now}},
+		{Err(err), Attribute{Key: "error", Value: err}},
+	}
+
+	for _, test := range tests {
+		// The dynamic types must match too, so that handlers format the values by type
+		if !reflect.DeepEqual(test.got, test.want) {
+			t.Errorf("got %#v, want %#v", test.got, test.want)
+		}
+	}
+}
+
+type testRequest struct {
+	id string
+}
+
+func (r testRequest) LogValue() any {
+	return []Attribute{String("id", r.id)}
+}
+
+func TestRecordAttrValueResolvesGroups(t *testing.T) {
+	// Never resolves to a group, AttrValue gives up after maxResolveDepth
+	var loop LazyValue
+	loop = func() any { return loop }
+
+	record := Record{
+		Message: "handled {req.id} for {user.name}",
+		Attributes: []Attribute{
+			{Key: "req", Value: testRequest{id: "42"}},
+			{Key: "user", Value: LazyValue(func() any { return []Attribute{String("name", "alice")} })},
+			{Key: "loop", Value: loop},
+		},
+	}
+
+	if got, ok := record.AttrValue("req.id"); !ok || got != "42" {
+		t.Errorf("req.id: got %v, %v, want 42, true", got, ok)
+	}
+
+	if got, ok := record.AttrValue("user.name"); !ok || got != "alice" {
+		t.Errorf("user.name: got %v, %v, want alice, true", got, ok)
+	}
+
+	for _, key := range []string{"req.missing", "loop.id"} {
+		if _, ok := record.AttrValue(key); ok {
+			t.Errorf("%s: got a value, want none", key)
+		}
+	}
+
+	if got, want := expandMessage(record), "handled 42 for alice"; got != want {
+		t.Errorf("expandMessage: got %q, want %q", got, want)
+	}
+}
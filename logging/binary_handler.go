@@ -0,0 +1,366 @@
+package logging
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"math"
+	"runtime"
+	"time"
+)
+
+// Wire format of a binary frame. All integers are varints unless noted otherwise.
+//
+//	frame     = length:uvarint payload
+//	payload   = version:byte time:varint(unix nanoseconds) level:varint kind:varint message:string
+//	            caller traceId:string spanId:string attrs
+//	caller    = 0x00 | 0x01 file:string line:varint function:string
+//	attrs     = count:uvarint { key:string value }
+//	value     = type:byte data
+//	string    = length:uvarint bytes
+//
+// Values of types other than those listed in binaryValueType are encoded as strings using
+// their default formatting. Version 1 frames, which have no kind, traceId and spanId, can
+// still be read.
+const binaryFormatVersion byte = 2
+
+// Frames longer than this are rejected as malformed instead of allocating their length
+const maxBinaryFrameSize = 16 << 20
+
+type binaryValueType byte
+
+const (
+	binaryValueType_Nil binaryValueType = iota
+	binaryValueType_String
+	binaryValueType_Int   // varint
+	binaryValueType_Uint  // uvarint
+	binaryValueType_Float // 8 byte little endian IEEE 754
+	binaryValueType_Bool  // 0x00 or 0x01
+	binaryValueType_Bytes // length:uvarint bytes
+	binaryValueType_Group // attrs
+)
+
+var ErrMalformedBinaryRecord = errors.New("malformed binary record")
+
+// BinaryHandler writes records as length-prefixed binary frames which can be read back
+// with [ReadBinaryRecords]
+type BinaryHandler struct {
+	writer io.Writer
+	level  Level
+}
+
+func NewBinaryHandler(writer io.Writer, level Level) BinaryHandler {
+	return BinaryHandler{writer: writer, level: level}
+}
+
+// Implements [logging.LeveledHandler]
+func (handler BinaryHandler) Level() Level {
+	return handler.level
+}
+
+// Implements [logging.Handler]
+func (handler BinaryHandler) OnLoggerCreated(logger *Logger, timestamp time.Time, caller *runtime.Frame) {
+}
+
+// Implements [logging.Handler]
+func (handler BinaryHandler) OnLoggerClosed(logger *Logger, timestamp time.Time, caller *runtime.Frame) error {
+	return nil
+}
+
+// Implements [logging.Handler]
+func (handler BinaryHandler) HandleRecord(logger *Logger, record Record) error {
+	if record.Level < handler.level {
+		return nil
+	}
+
+	record.Attributes = resolveAttrs(record.Attributes)
+	payload := appendBinaryRecord(nil, record)
+
+	frame := binary.AppendUvarint(make([]byte, 0, len(payload)+binary.MaxVarintLen64), uint64(len(payload)))
+	frame = append(frame, payload...)
+
+	return writeRecord(handler.writer, frame)
+}
+
+// ReadBinaryRecords reads the frames written by [BinaryHandler] from reader. Iteration stops
+// after the first error.
+func ReadBinaryRecords(reader io.Reader) iter.Seq2[Record, error] {
+	return func(yield func(Record, error) bool) {
+		r := bufio.NewReader(reader)
+
+		for {
+			length, err := binary.ReadUvarint(r)
+			if err == io.EOF {
+				return
+			} else if err != nil {
+				yield(Record{}, err)
+				return
+			}
+
+			if length > maxBinaryFrameSize {
+				yield(Record{}, fmt.Errorf("%w: frame of %d bytes exceeds the maximum of %d", ErrMalformedBinaryRecord, length, maxBinaryFrameSize))
+				return
+			}
+
+			payload := make([]byte, length)
+			if _, err := io.ReadFull(r, payload); err == io.EOF || err == io.ErrUnexpectedEOF {
+				yield(Record{}, fmt.Errorf("%w: unexpected end of stream", ErrMalformedBinaryRecord))
+				return
+			} else if err != nil {
+				yield(Record{}, err)
+				return
+			}
+
+			record, err := decodeBinaryRecord(payload)
+			if !yield(record, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+func appendBinaryRecord(buf []byte, record Record) []byte {
+	buf = append(buf, binaryFormatVersion)
+	buf = binary.AppendVarint(buf, record.Time.UnixNano())
+	buf = binary.AppendVarint(buf, int64(record.Level))
+	buf = binary.AppendVarint(buf, int64(record.Kind))
+	buf = appendBinaryString(buf, record.Message)
+
+	if record.Caller != nil {
+		buf = append(buf, 1)
+		buf = appendBinaryString(buf, record.Caller.File)
+		buf = binary.AppendVarint(buf, int64(record.Caller.Line))
+		buf = appendBinaryString(buf, record.Caller.Function)
+	} else {
+		buf = append(buf, 0)
+	}
+
+	buf = appendBinaryString(buf, record.TraceId)
+	buf = appendBinaryString(buf, record.SpanId)
+
+	return appendBinaryAttrs(buf, record.Attributes)
+}
+
+func appendBinaryString(buf []byte, s string) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(s)))
+	return append(buf, s...)
+}
+
+func appendBinaryAttrs(buf []byte, attrs []Attribute) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(attrs)))
+
+	for _, attr := range attrs {
+		buf = appendBinaryString(buf, attr.Key)
+		buf = appendBinaryValue(buf, attr.Value)
+	}
+
+	return buf
+}
+
+func appendBinaryValue(buf []byte, value any) []byte {
+	switch v := value.(type) {
+	case nil:
+		return append(buf, byte(binaryValueType_Nil))
+	case string:
+		return appendBinaryString(append(buf, byte(binaryValueType_String)), v)
+	case int:
+		return binary.AppendVarint(append(buf, byte(binaryValueType_Int)), int64(v))
+	case int8:
+		return binary.AppendVarint(append(buf, byte(binaryValueType_Int)), int64(v))
+	case int16:
+		return binary.AppendVarint(append(buf, byte(binaryValueType_Int)), int64(v))
+	case int32:
+		return binary.AppendVarint(append(buf, byte(binaryValueType_Int)), int64(v))
+	case int64:
+		return binary.AppendVarint(append(buf, byte(binaryValueType_Int)), v)
+	case uint:
+		return binary.AppendUvarint(append(buf, byte(binaryValueType_Uint)), uint64(v))
+	case uint8:
+		return binary.AppendUvarint(append(buf, byte(binaryValueType_Uint)), uint64(v))
+	case uint16:
+		return binary.AppendUvarint(append(buf, byte(binaryValueType_Uint)), uint64(v))
+	case uint32:
+		return binary.AppendUvarint(append(buf, byte(binaryValueType_Uint)), uint64(v))
+	case uint64:
+		return binary.AppendUvarint(append(buf, byte(binaryValueType_Uint)), v)
+	case float32:
+		return binary.LittleEndian.AppendUint64(append(buf, byte(binaryValueType_Float)), math.Float64bits(float64(v)))
+	case float64:
+		return binary.LittleEndian.AppendUint64(append(buf, byte(binaryValueType_Float)), math.Float64bits(v))
+	case bool:
+		if v {
+			return append(buf, byte(binaryValueType_Bool), 1)
+		}
+		return append(buf, byte(binaryValueType_Bool), 0)
+	case []byte:
+		return appendBinaryString(append(buf, byte(binaryValueType_Bytes)), string(v))
+	case []Attribute:
+		return appendBinaryAttrs(append(buf, byte(binaryValueType_Group)), v)
+	case error:
+		return appendBinaryString(append(buf, byte(binaryValueType_String)), v.Error())
+	default:
+		return appendBinaryString(append(buf, byte(binaryValueType_String)), fmt.Sprintf("%v", v))
+	}
+}
+
+type binaryDecoder struct {
+	buf   []byte
+	err   error
+	depth int
+}
+
+func decodeBinaryRecord(payload []byte) (Record, error) {
+	d := binaryDecoder{buf: payload}
+
+	version := d.byte()
+	if d.err == nil && version != 1 && version != binaryFormatVersion {
+		return Record{}, fmt.Errorf("%w: unsupported version %d", ErrMalformedBinaryRecord, version)
+	}
+
+	var record Record
+	record.Time = time.Unix(0, d.varint()).UTC()
+	record.Level = Level(d.varint())
+	if version >= 2 {
+		record.Kind = RecordKind(d.varint())
+	}
+	record.Message = d.string()
+
+	if hasCaller := d.byte(); hasCaller == 1 {
+		record.Caller = &runtime.Frame{}
+		record.Caller.File = d.string()
+		record.Caller.Line = int(d.varint())
+		record.Caller.Function = d.string()
+	}
+
+	if version >= 2 {
+		record.TraceId = d.string()
+		record.SpanId = d.string()
+	}
+
+	record.Attributes = d.attrs()
+
+	if d.err == nil && len(d.buf) > 0 {
+		d.err = fmt.Errorf("%w: %d trailing bytes", ErrMalformedBinaryRecord, len(d.buf))
+	}
+
+	if d.err != nil {
+		return Record{}, d.err
+	}
+
+	return record, nil
+}
+
+func (d *binaryDecoder) fail() {
+	if d.err == nil {
+		d.err = fmt.Errorf("%w: unexpected end of frame", ErrMalformedBinaryRecord)
+	}
+	d.buf = nil
+}
+
+func (d *binaryDecoder) byte() byte {
+	if len(d.buf) < 1 {
+		d.fail()
+		return 0
+	}
+
+	b := d.buf[0]
+	d.buf = d.buf[1:]
+	return b
+}
+
+func (d *binaryDecoder) varint() int64 {
+	v, n := binary.Varint(d.buf)
+	if n <= 0 {
+		d.fail()
+		return 0
+	}
+
+	d.buf = d.buf[n:]
+	return v
+}
+
+func (d *binaryDecoder) uvarint() uint64 {
+	v, n := binary.Uvarint(d.buf)
+	if n <= 0 {
+		d.fail()
+		return 0
+	}
+
+	d.buf = d.buf[n:]
+	return v
+}
+
+func (d *binaryDecoder) bytes() []byte {
+	length := d.uvarint()
+	if uint64(len(d.buf)) < length {
+		d.fail()
+		return nil
+	}
+
+	b := d.buf[:length]
+	d.buf = d.buf[length:]
+	return b
+}
+
+func (d *binaryDecoder) string() string {
+	return string(d.bytes())
+}
+
+func (d *binaryDecoder) attrs() []Attribute {
+	count := d.uvarint()
+
+	attrs := make([]Attribute, 0)
+	for i := uint64(0); i < count && d.err == nil; i++ {
+		key := d.string()
+		attrs = append(attrs, Attribute{Key: key, Value: d.value()})
+	}
+
+	return attrs
+}
+
+func (d *binaryDecoder) value() any {
+	switch t := binaryValueType(d.byte()); t {
+	case binaryValueType_Nil:
+		return nil
+	case binaryValueType_String:
+		return d.string()
+	case binaryValueType_Int:
+		return d.varint()
+	case binaryValueType_Uint:
+		return d.uvarint()
+	case binaryValueType_Float:
+		if len(d.buf) < 8 {
+			d.fail()
+			return nil
+		}
+
+		v := math.Float64frombits(binary.LittleEndian.Uint64(d.buf))
+		d.buf = d.buf[8:]
+		return v
+	case binaryValueType_Bool:
+		return d.byte() == 1
+	case binaryValueType_Bytes:
+		return append([]byte(nil), d.bytes()...)
+	case binaryValueType_Group:
+		// Bound the recursion so that a frame of nested groups cannot exhaust the stack
+		if d.depth++; d.depth > maxResolveDepth {
+			if d.err == nil {
+				d.err = fmt.Errorf("%w: groups nested deeper than %d", ErrMalformedBinaryRecord, maxResolveDepth)
+			}
+			d.buf = nil
+			return nil
+		}
+
+		attrs := d.attrs()
+		d.depth--
+		return attrs
+	default:
+		if d.err == nil {
+			d.err = fmt.Errorf("%w: unknown value type %d", ErrMalformedBinaryRecord, t)
+		}
+		return nil
+	}
+}
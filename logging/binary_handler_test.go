@@ -0,0 +1,82 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestBinaryHandlerRoundTrip(t *testing.T) {
+	want := Record{
+		Time:    time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC),
+		Level:   LevelWarn,
+		Kind:    RecordKind_Metric,
+		Message: "hello",
+		Caller:  &runtime.Frame{File: "main.go", Line: 12, Function: "main.main"},
+		TraceId: "trace",
+		SpanId:  "span",
+		Attributes: []Attribute{
+			{Key: "int", Value: int64(-3)},
+			{Key: "uint", Value: uint64(3)},
+			{Key: "float", Value: 1.5},
+			{Key: "bool", Value: true},
+			{Key: "string", Value: "s"},
+			{Key: "nil", Value: nil},
+			{Key: "bytes", Value: []byte{1, 2}},
+			{Key: "group", Value: []Attribute{{Key: "a", Value: "b"}}},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := NewBinaryHandler(&buf, LevelTrace).HandleRecord(NewLogger(), want); err != nil {
+		t.Fatal(err)
+	}
+
+	records := 0
+	for got, err := range ReadBinaryRecords(&buf) {
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v, want %+v", got, want)
+		}
+
+		records++
+	}
+
+	if records != 1 {
+		t.Errorf("read %d records, want 1", records)
+	}
+}
+
+func TestReadBinaryRecordsMalformed(t *testing.T) {
+	tests := map[string][]byte{
+		"huge length":   binary.AppendUvarint(nil, 1<<62),
+		"short payload": binary.AppendUvarint(nil, 10),
+		"deep groups": func() []byte {
+			payload := []byte{binaryFormatVersion, 0, 0, 0, 0, 0, 0, 0, 1, 0}
+			for range maxResolveDepth + 1 {
+				payload = append(payload, byte(binaryValueType_Group), 1, 0)
+			}
+
+			return append(binary.AppendUvarint(nil, uint64(len(payload))), payload...)
+		}(),
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			var err error
+			for _, err = range ReadBinaryRecords(bytes.NewReader(data)) {
+			}
+
+			if !errors.Is(err, ErrMalformedBinaryRecord) {
+				t.Errorf("got error %v, want %v", err, ErrMalformedBinaryRecord)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package logging
+
+import (
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+type CallerStyle int
+
+const (
+	// Path relative to the project root, or the file name if the file is outside of it
+	CallerStyle_ModuleRel CallerStyle = iota
+	// File name only
+	CallerStyle_Short
+	// Absolute path as recorded by the compiler
+	CallerStyle_Full
+)
+
+// CallerFormatter renders the path of a caller frame, without the line
+type CallerFormatter func(frame *runtime.Frame) string
+
+// FormatCaller renders the path of frame in style. It returns an empty string if frame is nil.
+func FormatCaller(frame *runtime.Frame, style CallerStyle) string {
+	if frame == nil {
+		return ""
+	}
+
+	return formatCallerPath(frame.File, style, projectRoot)
+}
+
+func formatCallerPath(file string, style CallerStyle, basePath string) string {
+	switch style {
+	case CallerStyle_Short:
+		return filepath.Base(file)
+	case CallerStyle_Full:
+		return file
+	default:
+		return relativeCallerPath(file, basePath)
+	}
+}
+
+// relativeCallerPath returns the path of file relative to basePath, or only the file name if
+// it is outside of basePath
+func relativeCallerPath(file string, basePath string) string {
+	relativePath, err := filepath.Rel(basePath, file)
+	if err != nil || relativePath == ".." || strings.HasPrefix(relativePath, ".."+string(filepath.Separator)) {
+		return filepath.Base(file)
+	}
+
+	return relativePath
+}
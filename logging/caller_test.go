@@ -0,0 +1,66 @@
+// The tests are in an external package, since frames of the logging package, including
+// those of its own tests, are skipped when looking for the caller
+package logging_test
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/link00000000/go-telemetry/logging"
+)
+
+// The caller must be found whether or not the logging functions are inlined, so this test
+// should also pass when compiled with -gcflags=-l
+func TestCaller(t *testing.T) {
+	var caller *runtime.Frame
+	handler := logging.NewFuncHandler(logging.LevelTrace, func(logger *logging.Logger, record logging.Record) error {
+		caller = record.Caller
+		return nil
+	})
+
+	logger := logging.NewLogger()
+	logger.AddHandler(handler)
+
+	child := logger.NewChildLogger()
+
+	tests := map[string]func() int{
+		"Info": func() int {
+			logger.Info("message")
+			return line()
+		},
+		"Log": func() int {
+			logger.Log(logging.LevelWarn, "message")
+			return line()
+		},
+		"LogAttrs": func() int {
+			logger.LogAttrs(logging.LevelError, "message")
+			return line()
+		},
+		"Metric": func() int {
+			logger.Metric("metric", 1)
+			return line()
+		},
+		"child": func() int {
+			child.Debug("message")
+			return line()
+		},
+	}
+
+	for name, log := range tests {
+		caller = nil
+		want := log() - 1
+
+		_, file, _, _ := runtime.Caller(0)
+		if caller == nil {
+			t.Errorf("%s: no caller", name)
+		} else if caller.File != file || caller.Line != want {
+			t.Errorf("%s: got caller %s:%d (%s), want %s:%d", name, caller.File, caller.Line, caller.Function, file, want)
+		}
+	}
+}
+
+// line returns the line it is called from
+func line() int {
+	_, _, line, _ := runtime.Caller(1)
+	return line
+}
@@ -0,0 +1,439 @@
+package logging
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"math"
+	"runtime"
+	"time"
+)
+
+// Each record is written as a single CBOR (RFC 8949) map, so records need no delimiter:
+//
+//	{
+//	  "time": unix nanoseconds,
+//	  "level": text,
+//	  "kind": text,
+//	  "message": text,
+//	  "caller": {"file": text, "line": int, "function": text} or null,
+//	  "attributes": {key: value, ...}
+//	}
+//
+// Attribute values are encoded as the matching CBOR type. Groups are nested maps, durations
+// are integer nanoseconds, times are RFC 3339 strings tagged as date/time (tag 0), errors are
+// their messages and other values are text using their default formatting.
+
+var ErrMalformedCborRecord = errors.New("malformed CBOR record")
+
+// Strings longer than this are rejected as malformed instead of allocating their length
+const maxCborStringSize = 16 << 20
+
+const (
+	cborMajor_Uint   byte = 0 << 5
+	cborMajor_Negint byte = 1 << 5
+	cborMajor_Bytes  byte = 2 << 5
+	cborMajor_Text   byte = 3 << 5
+	cborMajor_Array  byte = 4 << 5
+	cborMajor_Map    byte = 5 << 5
+	cborMajor_Tag    byte = 6 << 5
+	cborMajor_Simple byte = 7 << 5
+)
+
+const (
+	cborFalse   byte = cborMajor_Simple | 20
+	cborTrue    byte = cborMajor_Simple | 21
+	cborNull    byte = cborMajor_Simple | 22
+	cborFloat32 byte = cborMajor_Simple | 26
+	cborFloat64 byte = cborMajor_Simple | 27
+
+	cborTag_DateTime = 0
+)
+
+// CborHandler writes each record as a CBOR map, see [ReadCborRecords] to read them back
+type CborHandler struct {
+	writer io.Writer
+	level  Level
+}
+
+func NewCborHandler(writer io.Writer, level Level) CborHandler {
+	return CborHandler{writer: writer, level: level}
+}
+
+// Implements [logging.LeveledHandler]
+func (handler CborHandler) Level() Level {
+	return handler.level
+}
+
+// Implements [logging.Handler]
+func (handler CborHandler) OnLoggerCreated(logger *Logger, timestamp time.Time, caller *runtime.Frame) {
+}
+
+// Implements [logging.Handler]
+func (handler CborHandler) OnLoggerClosed(logger *Logger, timestamp time.Time, caller *runtime.Frame) error {
+	return nil
+}
+
+// Implements [logging.Handler]
+func (handler CborHandler) HandleRecord(logger *Logger, record Record) error {
+	if record.Level < handler.level {
+		return nil
+	}
+
+	return writeRecord(handler.writer, appendCborRecord(nil, record))
+}
+
+func appendCborRecord(buf []byte, record Record) []byte {
+	buf = appendCborHead(buf, cborMajor_Map, 6)
+
+	buf = appendCborText(buf, "time")
+	buf = appendCborInt(buf, record.Time.UnixNano())
+	buf = appendCborText(buf, "level")
+	buf = appendCborText(buf, record.Level.String())
+	buf = appendCborText(buf, "kind")
+	buf = appendCborText(buf, record.Kind.String())
+	buf = appendCborText(buf, "message")
+	buf = appendCborText(buf, record.Message)
+
+	buf = appendCborText(buf, "caller")
+	if record.Caller != nil {
+		buf = appendCborHead(buf, cborMajor_Map, 3)
+		buf = appendCborText(buf, "file")
+		buf = appendCborText(buf, record.Caller.File)
+		buf = appendCborText(buf, "line")
+		buf = appendCborInt(buf, int64(record.Caller.Line))
+		buf = appendCborText(buf, "function")
+		buf = appendCborText(buf, record.Caller.Function)
+	} else {
+		buf = append(buf, cborNull)
+	}
+
+	// Reuse the JSON conversion to merge duplicate keys, which are not allowed in CBOR maps
+	buf = appendCborText(buf, "attributes")
+	return appendCborAttrs(buf, jsonAttributes(resolveAttrs(record.Attributes), nil))
+}
+
+// appendCborHead appends the initial byte of an item of major type with argument n, followed
+// by n in the smallest size that fits it
+func appendCborHead(buf []byte, major byte, n uint64) []byte {
+	switch {
+	case n < 24:
+		return append(buf, major|byte(n))
+	case n <= math.MaxUint8:
+		return append(buf, major|24, byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(buf, major|25), uint16(n))
+	case n <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(buf, major|26), uint32(n))
+	default:
+		return binary.BigEndian.AppendUint64(append(buf, major|27), n)
+	}
+}
+
+func appendCborInt(buf []byte, v int64) []byte {
+	if v < 0 {
+		return appendCborHead(buf, cborMajor_Negint, uint64(-1-v))
+	}
+
+	return appendCborHead(buf, cborMajor_Uint, uint64(v))
+}
+
+func appendCborText(buf []byte, s string) []byte {
+	return append(appendCborHead(buf, cborMajor_Text, uint64(len(s))), s...)
+}
+
+func appendCborAttrs(buf []byte, attrs JsonHandlerAttributes) []byte {
+	buf = appendCborHead(buf, cborMajor_Map, uint64(len(attrs)))
+
+	for _, attr := range attrs {
+		buf = appendCborText(buf, attr.Key)
+		buf = appendCborValue(buf, attr.Value)
+	}
+
+	return buf
+}
+
+func appendCborValue(buf []byte, value any) []byte {
+	switch v := value.(type) {
+	case nil:
+		return append(buf, cborNull)
+	case string:
+		return appendCborText(buf, v)
+	case int:
+		return appendCborInt(buf, int64(v))
+	case int8:
+		return appendCborInt(buf, int64(v))
+	case int16:
+		return appendCborInt(buf, int64(v))
+	case int32:
+		return appendCborInt(buf, int64(v))
+	case int64:
+		return appendCborInt(buf, v)
+	case uint:
+		return appendCborHead(buf, cborMajor_Uint, uint64(v))
+	case uint8:
+		return appendCborHead(buf, cborMajor_Uint, uint64(v))
+	case uint16:
+		return appendCborHead(buf, cborMajor_Uint, uint64(v))
+	case uint32:
+		return appendCborHead(buf, cborMajor_Uint, uint64(v))
+	case uint64:
+		return appendCborHead(buf, cborMajor_Uint, v)
+	case float32:
+		return binary.BigEndian.AppendUint32(append(buf, cborFloat32), math.Float32bits(v))
+	case float64:
+		return binary.BigEndian.AppendUint64(append(buf, cborFloat64), math.Float64bits(v))
+	case bool:
+		if v {
+			return append(buf, cborTrue)
+		}
+		return append(buf, cborFalse)
+	case []byte:
+		return append(appendCborHead(buf, cborMajor_Bytes, uint64(len(v))), v...)
+	case time.Duration:
+		return appendCborInt(buf, int64(v))
+	case Bytes:
+		return appendCborInt(buf, int64(v))
+	case time.Time:
+		buf = appendCborHead(buf, cborMajor_Tag, cborTag_DateTime)
+		return appendCborText(buf, v.Format(time.RFC3339Nano))
+	case JsonHandlerAttributes:
+		return appendCborAttrs(buf, v)
+	default:
+		return appendCborText(buf, fmt.Sprintf("%v", v))
+	}
+}
+
+// ReadCborRecords reads the records written by [CborHandler] from reader. Integers are
+// returned as int64, or uint64 if they do not fit, and groups as []Attribute. Iteration
+// stops after the first error.
+func ReadCborRecords(reader io.Reader) iter.Seq2[Record, error] {
+	return func(yield func(Record, error) bool) {
+		d := cborDecoder{reader: bufio.NewReader(reader)}
+
+		for {
+			if _, err := d.reader.Peek(1); err == io.EOF {
+				return
+			}
+
+			record, err := d.record()
+			if !yield(record, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+type cborDecoder struct {
+	reader *bufio.Reader
+	depth  int
+}
+
+func (d *cborDecoder) malformed(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrMalformedCborRecord, fmt.Sprintf(format, args...))
+}
+
+// head reads the initial byte of an item and its argument
+func (d *cborDecoder) head() (major byte, info byte, n uint64, err error) {
+	b, err := d.reader.ReadByte()
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	major, info = b&0xe0, b&0x1f
+
+	var size int
+	switch {
+	case info < 24:
+		return major, info, uint64(info), nil
+	case info == 24:
+		size = 1
+	case info == 25:
+		size = 2
+	case info == 26:
+		size = 4
+	case info == 27:
+		size = 8
+	default:
+		return 0, 0, 0, d.malformed("unsupported additional information %d", info)
+	}
+
+	var arg [8]byte
+	if _, err := io.ReadFull(d.reader, arg[8-size:]); err != nil {
+		return 0, 0, 0, err
+	}
+
+	return major, info, binary.BigEndian.Uint64(arg[:]), nil
+}
+
+func (d *cborDecoder) bytes(n uint64) ([]byte, error) {
+	if n > maxCborStringSize {
+		return nil, d.malformed("string of %d bytes exceeds the maximum of %d", n, maxCborStringSize)
+	}
+
+	b := make([]byte, n)
+	if _, err := io.ReadFull(d.reader, b); err == io.EOF || err == io.ErrUnexpectedEOF {
+		return nil, d.malformed("unexpected end of stream")
+	} else if err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+func (d *cborDecoder) value() (any, error) {
+	major, info, n, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+
+	// Bound the recursion so that deeply nested arrays and maps cannot exhaust the stack
+	if major == cborMajor_Array || major == cborMajor_Map || major == cborMajor_Tag {
+		if d.depth++; d.depth > maxResolveDepth {
+			return nil, d.malformed("items nested deeper than %d", maxResolveDepth)
+		}
+		defer func() { d.depth-- }()
+	}
+
+	switch major {
+	case cborMajor_Uint:
+		if n > math.MaxInt64 {
+			return n, nil
+		}
+		return int64(n), nil
+	case cborMajor_Negint:
+		if n > math.MaxInt64 {
+			return nil, d.malformed("negative integer out of range")
+		}
+		return -1 - int64(n), nil
+	case cborMajor_Bytes:
+		return d.bytes(n)
+	case cborMajor_Text:
+		b, err := d.bytes(n)
+		return string(b), err
+	case cborMajor_Array:
+		values := make([]any, 0)
+		for i := uint64(0); i < n; i++ {
+			value, err := d.value()
+			if err != nil {
+				return nil, err
+			}
+
+			values = append(values, value)
+		}
+		return values, nil
+	case cborMajor_Map:
+		return d.attrs(n)
+	case cborMajor_Tag:
+		value, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+
+		if s, ok := value.(string); ok && n == cborTag_DateTime {
+			return time.Parse(time.RFC3339Nano, s)
+		}
+		return value, nil
+	default:
+		switch {
+		case info == 20:
+			return false, nil
+		case info == 21:
+			return true, nil
+		case info == 22:
+			return nil, nil
+		case info == 26:
+			return float64(math.Float32frombits(uint32(n))), nil
+		case info == 27:
+			return math.Float64frombits(n), nil
+		default:
+			return nil, d.malformed("unsupported simple value %d", info)
+		}
+	}
+}
+
+// attrs reads the n entries of a map with text keys
+func (d *cborDecoder) attrs(n uint64) ([]Attribute, error) {
+	attrs := make([]Attribute, 0)
+	for i := uint64(0); i < n; i++ {
+		key, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+
+		s, ok := key.(string)
+		if !ok {
+			return nil, d.malformed("map key %v is not text", key)
+		}
+
+		value, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+
+		attrs = append(attrs, Attribute{Key: s, Value: value})
+	}
+
+	return attrs, nil
+}
+
+func (d *cborDecoder) record() (Record, error) {
+	value, err := d.value()
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+
+	if err != nil {
+		return Record{}, err
+	}
+
+	fields, ok := value.([]Attribute)
+	if !ok {
+		return Record{}, d.malformed("record is not a map")
+	}
+
+	var record Record
+	for _, field := range fields {
+		switch field.Key {
+		case "time":
+			n, _ := field.Value.(int64)
+			record.Time = time.Unix(0, n).UTC()
+		case "level":
+			s, _ := field.Value.(string)
+			if record.Level, err = ParseLevel(s); err != nil {
+				return Record{}, err
+			}
+		case "kind":
+			if field.Value == RecordKind_Metric.String() {
+				record.Kind = RecordKind_Metric
+			}
+		case "message":
+			record.Message, _ = field.Value.(string)
+		case "caller":
+			caller, ok := field.Value.([]Attribute)
+			if !ok {
+				continue
+			}
+
+			record.Caller = &runtime.Frame{}
+			for _, attr := range caller {
+				switch attr.Key {
+				case "file":
+					record.Caller.File, _ = attr.Value.(string)
+				case "line":
+					line, _ := attr.Value.(int64)
+					record.Caller.Line = int(line)
+				case "function":
+					record.Caller.Function, _ = attr.Value.(string)
+				}
+			}
+		case "attributes":
+			record.Attributes, _ = field.Value.([]Attribute)
+		}
+	}
+
+	return record, nil
+}
@@ -0,0 +1,71 @@
+package logging
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestCborHandlerRoundTrip(t *testing.T) {
+	want := Record{
+		Time:    time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC),
+		Level:   LevelWarn,
+		Kind:    RecordKind_Metric,
+		Message: "hello",
+		Caller:  &runtime.Frame{File: "main.go", Line: 12, Function: "main.main"},
+		Attributes: []Attribute{
+			{Key: "int", Value: int64(-3)},
+			{Key: "uint", Value: uint64(1 << 63)},
+			{Key: "float", Value: 1.5},
+			{Key: "bool", Value: true},
+			{Key: "string", Value: "s"},
+			{Key: "nil", Value: nil},
+			{Key: "group", Value: []Attribute{{Key: "a", Value: "b"}}},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := NewCborHandler(&buf, LevelTrace).HandleRecord(NewLogger(), want); err != nil {
+		t.Fatal(err)
+	}
+
+	records := 0
+	for got, err := range ReadCborRecords(&buf) {
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v, want %+v", got, want)
+		}
+
+		records++
+	}
+
+	if records != 1 {
+		t.Errorf("read %d records, want 1", records)
+	}
+}
+
+func TestReadCborRecordsMalformed(t *testing.T) {
+	tests := map[string][]byte{
+		"huge length":  {cborMajor_Text | 27, 0x40, 0, 0, 0, 0, 0, 0, 0},
+		"short string": {cborMajor_Text | 10, 'a'},
+		"deep arrays":  bytes.Repeat([]byte{cborMajor_Array | 1}, maxResolveDepth+1),
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			var err error
+			for _, err = range ReadCborRecords(bytes.NewReader(data)) {
+			}
+
+			if !errors.Is(err, ErrMalformedCborRecord) {
+				t.Errorf("got error %v, want %v", err, ErrMalformedCborRecord)
+			}
+		})
+	}
+}
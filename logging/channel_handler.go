@@ -0,0 +1,93 @@
+package logging
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ChannelHandler sends records to a buffered channel for consumption by another goroutine,
+// such as a live log viewer. Records are dropped instead of blocking the logger when the
+// channel is full.
+type ChannelHandler struct {
+	mutex sync.RWMutex
+
+	level   Level
+	ch      chan Record
+	closed  bool
+	dropped atomic.Uint64
+}
+
+func NewChannelHandler(capacity int, level Level) *ChannelHandler {
+	return &ChannelHandler{level: level, ch: make(chan Record, capacity)}
+}
+
+// C returns the channel receiving the records. It is closed when the handler is closed.
+func (handler *ChannelHandler) C() <-chan Record {
+	return handler.ch
+}
+
+// Dropped returns the number of records dropped because the channel was full
+func (handler *ChannelHandler) Dropped() uint64 {
+	return handler.dropped.Load()
+}
+
+// Close closes the channel. Records handled afterwards are dropped without being counted.
+func (handler *ChannelHandler) Close() error {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	if !handler.closed {
+		handler.closed = true
+		close(handler.ch)
+	}
+
+	return nil
+}
+
+// Implements [logging.ContextCloser], so that consumers ranging over the channel stop when
+// the logger is closed
+func (handler *ChannelHandler) CloseContext(ctx context.Context) error {
+	return handler.Close()
+}
+
+// Implements [logging.LeveledHandler]
+func (handler *ChannelHandler) Level() Level {
+	return handler.level
+}
+
+// Implements [logging.Handler]
+func (handler *ChannelHandler) OnLoggerCreated(logger *Logger, timestamp time.Time, caller *runtime.Frame) {
+}
+
+// Implements [logging.Handler]
+func (handler *ChannelHandler) OnLoggerClosed(logger *Logger, timestamp time.Time, caller *runtime.Frame) error {
+	return nil
+}
+
+// Implements [logging.Handler]
+func (handler *ChannelHandler) HandleRecord(logger *Logger, record Record) error {
+	if record.Level < handler.level {
+		return nil
+	}
+
+	// Evaluate lazy values now rather than on the consumer's goroutine
+	record.Attributes = resolveAttrs(record.Attributes)
+
+	handler.mutex.RLock()
+	defer handler.mutex.RUnlock()
+
+	if handler.closed {
+		return nil
+	}
+
+	select {
+	case handler.ch <- record:
+	default:
+		handler.dropped.Add(1)
+	}
+
+	return nil
+}
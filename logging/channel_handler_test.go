@@ -0,0 +1,40 @@
+package logging
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestChannelHandlerClosedWithLogger(t *testing.T) {
+	handler := NewChannelHandler(8, LevelTrace)
+
+	logger := NewLogger()
+	logger.AddHandler(handler)
+
+	done := make(chan []string)
+	go func() {
+		var messages []string
+		for record := range handler.C() {
+			messages = append(messages, record.Message)
+		}
+
+		done <- messages
+	}()
+
+	logger.Info("first")
+	logger.Info("second")
+
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case messages := <-done:
+		if want := []string{"first", "second"}; !slices.Equal(messages, want) {
+			t.Errorf("got messages %q, want %q", messages, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("channel was not closed with the logger")
+	}
+}
@@ -0,0 +1,183 @@
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type chromeTraceEvent struct {
+	Name  string                `json:"name"`
+	Phase string                `json:"ph"`
+	Scope string                `json:"s,omitempty"`
+	Time  float64               `json:"ts"`
+	Pid   int                   `json:"pid"`
+	Tid   int                   `json:"tid"`
+	Args  JsonHandlerAttributes `json:"args,omitempty"`
+}
+
+// ChromeTraceHandler writes the lifetime of each logger as begin and end events, and each
+// record as an instant event, in the Chrome Trace Event Format. Each level of the logger
+// tree is shown on its own track. The output can be loaded in chrome://tracing or Perfetto.
+type ChromeTraceHandler struct {
+	mutex sync.Mutex
+
+	writer  io.Writer
+	level   Level
+	started map[uuid.UUID]bool
+	written bool
+	closed  bool
+}
+
+func NewChromeTraceHandler(writer io.Writer, level Level) *ChromeTraceHandler {
+	return &ChromeTraceHandler{
+		writer:  writer,
+		level:   level,
+		started: make(map[uuid.UUID]bool),
+	}
+}
+
+func loggerDepth(logger *Logger) int {
+	depth := 0
+	for l := logger.parent; l != nil; l = l.parent {
+		depth++
+	}
+
+	return depth
+}
+
+func chromeTraceTime(t time.Time) float64 {
+	return float64(t.UnixNano()) / float64(time.Microsecond)
+}
+
+// writeEvent writes event as the next element of the trace array. The caller must hold the
+// mutex.
+func (handler *ChromeTraceHandler) writeEvent(event chromeTraceEvent) error {
+	if handler.closed {
+		return nil
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	if !handler.written {
+		data = append([]byte("[\n"), data...)
+		handler.written = true
+	} else {
+		data = append([]byte(",\n"), data...)
+	}
+
+	return writeRecord(handler.writer, data)
+}
+
+// begin writes the begin event for logger if it has not been written yet. Root loggers are
+// never reported as created, so they begin when they are first seen. The caller must hold
+// the mutex.
+func (handler *ChromeTraceHandler) begin(logger *Logger, timestamp time.Time) error {
+	if handler.started[logger.id] {
+		return nil
+	}
+
+	handler.started[logger.id] = true
+
+	return handler.writeEvent(chromeTraceEvent{
+		Name:  logger.id.String(),
+		Phase: "B",
+		Time:  chromeTraceTime(timestamp),
+		Pid:   1,
+		Tid:   loggerDepth(logger),
+	})
+}
+
+// Close terminates the trace array. Further events are dropped.
+func (handler *ChromeTraceHandler) Close() error {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	if handler.closed {
+		return nil
+	}
+
+	handler.closed = true
+
+	if !handler.written {
+		_, err := io.WriteString(handler.writer, "[]\n")
+		return err
+	}
+
+	_, err := io.WriteString(handler.writer, "\n]\n")
+	return err
+}
+
+// Implements [logging.ContextCloser], so that the trace is complete once the logger it is
+// attached to is closed
+func (handler *ChromeTraceHandler) CloseContext(ctx context.Context) error {
+	return handler.Close()
+}
+
+// Implements [logging.LeveledHandler]
+func (handler *ChromeTraceHandler) Level() Level {
+	return handler.level
+}
+
+// Implements [logging.Handler]
+func (handler *ChromeTraceHandler) OnLoggerCreated(logger *Logger, timestamp time.Time, caller *runtime.Frame) {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	handler.begin(logger, timestamp)
+}
+
+// Implements [logging.Handler]
+func (handler *ChromeTraceHandler) OnLoggerClosed(logger *Logger, timestamp time.Time, caller *runtime.Frame) error {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	if err := handler.begin(logger, logger.createdAt); err != nil {
+		return err
+	}
+
+	delete(handler.started, logger.id)
+
+	return handler.writeEvent(chromeTraceEvent{
+		Name:  logger.id.String(),
+		Phase: "E",
+		Time:  chromeTraceTime(timestamp),
+		Pid:   1,
+		Tid:   loggerDepth(logger),
+	})
+}
+
+// Implements [logging.Handler]
+func (handler *ChromeTraceHandler) HandleRecord(logger *Logger, record Record) error {
+	if record.Level < handler.level {
+		return nil
+	}
+
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	if err := handler.begin(logger, logger.createdAt); err != nil {
+		return err
+	}
+
+	args := jsonAttributes(resolveAttrs(record.Attributes), nil)
+	args = args.set("level", record.Level.String())
+
+	return handler.writeEvent(chromeTraceEvent{
+		Name:  record.Message,
+		Phase: "i",
+		Scope: "t",
+		Time:  chromeTraceTime(record.Time),
+		Pid:   1,
+		Tid:   loggerDepth(logger),
+		Args:  args,
+	})
+}
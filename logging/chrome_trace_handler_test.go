@@ -0,0 +1,39 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestChromeTraceHandlerClosedWithLogger(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger := NewLogger()
+	logger.AddHandler(NewChromeTraceHandler(&buf, LevelTrace))
+
+	logger.Info("message")
+
+	child := logger.NewChildLogger()
+	child.Info("message")
+
+	// Closing the logger must terminate the array so that the output is valid JSON
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var events []chromeTraceEvent
+	if err := json.Unmarshal(buf.Bytes(), &events); err != nil {
+		t.Fatalf("invalid trace %q: %v", buf.String(), err)
+	}
+
+	phases := make([]string, len(events))
+	for i, event := range events {
+		phases[i] = event.Phase
+	}
+
+	if want := []string{"B", "i", "B", "i", "E", "E"}; !slices.Equal(phases, want) {
+		t.Errorf("got phases %q, want %q", phases, want)
+	}
+}
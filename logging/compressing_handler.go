@@ -0,0 +1,159 @@
+package logging
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// CompressingHandler writes records as gzip-compressed newline delimited JSON
+type CompressingHandler struct {
+	mutex sync.Mutex
+
+	level  Level
+	closer io.Closer
+	gzip   *gzip.Writer
+	json   JsonHandler
+
+	stopFlushing chan struct{}
+}
+
+func NewCompressingHandler(writer io.Writer, level Level) *CompressingHandler {
+	gz := gzip.NewWriter(writer)
+
+	return &CompressingHandler{
+		level: level,
+		gzip:  gz,
+		json:  NewJsonHandler(gz, level),
+	}
+}
+
+// NewCompressingFileHandler appends compressed records to the file at path, creating it if
+// necessary. The file is closed when the handler is closed.
+func NewCompressingFileHandler(path string, level Level) (*CompressingHandler, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	handler := NewCompressingHandler(file, level)
+	handler.closer = file
+
+	return handler, nil
+}
+
+// FlushEvery periodically flushes the gzip stream so that records become readable without
+// waiting for [CompressingHandler.Close]. The flushing stops when the handler is closed.
+func (handler *CompressingHandler) FlushEvery(interval time.Duration) {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	if handler.stopFlushing != nil {
+		close(handler.stopFlushing)
+	}
+
+	stop := make(chan struct{})
+	handler.stopFlushing = stop
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				handler.Flush()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// Flush writes any buffered compressed data to the underlying writer
+func (handler *CompressingHandler) Flush() error {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	return handler.gzip.Flush()
+}
+
+// Reset finishes the current gzip stream and starts a new one on writer, so that each
+// writer receives an independently decompressible stream. This is intended for rotating
+// the output between files.
+func (handler *CompressingHandler) Reset(writer io.Writer) error {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	err := handler.gzip.Close()
+	handler.gzip.Reset(writer)
+
+	return err
+}
+
+// Implements [io.Closer]
+func (handler *CompressingHandler) Close() error {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	if handler.stopFlushing != nil {
+		close(handler.stopFlushing)
+		handler.stopFlushing = nil
+	}
+
+	errs := []error{handler.gzip.Close()}
+	if handler.closer != nil {
+		errs = append(errs, handler.closer.Close())
+		handler.closer = nil
+	}
+
+	return errors.Join(errs...)
+}
+
+// Implements [logging.ContextCloser], so that the gzip stream is terminated when the logger
+// is closed
+func (handler *CompressingHandler) CloseContext(ctx context.Context) error {
+	done := make(chan error, 1)
+	go func() { done <- handler.Close() }()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Implements [logging.LeveledHandler]
+func (handler *CompressingHandler) Level() Level {
+	return handler.level
+}
+
+// Implements [logging.Handler]
+func (handler *CompressingHandler) OnLoggerCreated(logger *Logger, timestamp time.Time, caller *runtime.Frame) {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	handler.json.OnLoggerCreated(logger, timestamp, caller)
+}
+
+// Implements [logging.Handler]
+func (handler *CompressingHandler) OnLoggerClosed(logger *Logger, timestamp time.Time, caller *runtime.Frame) error {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	return handler.json.OnLoggerClosed(logger, timestamp, caller)
+}
+
+// Implements [logging.Handler]
+func (handler *CompressingHandler) HandleRecord(logger *Logger, record Record) error {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	return handler.json.HandleRecord(logger, record)
+}
@@ -0,0 +1,65 @@
+package logging
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCompressingHandlerClosedWithLogger(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger := NewLogger()
+	logger.AddHandler(NewCompressingHandler(&buf, LevelTrace))
+
+	logger.Info("first")
+	logger.Info("second")
+
+	// Closing the logger must terminate the gzip stream so that the output can be read back
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var messages []string
+	types := make([]JsonHandlerMessageType, 0)
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var message struct {
+			Type JsonHandlerMessageType `json:"type"`
+			Data struct {
+				Message string `json:"message"`
+			} `json:"data"`
+		}
+
+		if err := json.Unmarshal([]byte(line), &message); err != nil {
+			t.Fatalf("invalid line %q: %v", line, err)
+		}
+
+		types = append(types, message.Type)
+		if message.Type == JsonHandlerMessageType_Record {
+			messages = append(messages, message.Data.Message)
+		}
+	}
+
+	wantTypes := []JsonHandlerMessageType{JsonHandlerMessageType_Record, JsonHandlerMessageType_Record, JsonHandlerMessageType_LoggerClosed}
+	if !slices.Equal(types, wantTypes) {
+		t.Errorf("got message types %v, want %v", types, wantTypes)
+	}
+
+	if want := []string{"first", "second"}; !slices.Equal(messages, want) {
+		t.Errorf("got messages %q, want %q", messages, want)
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !windows
+
+package logging
+
+// enableVirtualTerminal reports whether escape codes will be interpreted by the terminal
+// behind fd, which is always the case outside of Windows
+func enableVirtualTerminal(fd uintptr) bool {
+	return true
+}
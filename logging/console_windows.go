@@ -0,0 +1,22 @@
+//go:build windows
+
+package logging
+
+import "golang.org/x/sys/windows"
+
+// enableVirtualTerminal turns on ANSI escape code processing for the console behind fd and
+// reports whether escape codes will be interpreted
+func enableVirtualTerminal(fd uintptr) bool {
+	handle := windows.Handle(fd)
+
+	var mode uint32
+	if err := windows.GetConsoleMode(handle, &mode); err != nil {
+		return false
+	}
+
+	if mode&windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING != 0 {
+		return true
+	}
+
+	return windows.SetConsoleMode(handle, mode|windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING) == nil
+}
@@ -0,0 +1,65 @@
+package logging
+
+import (
+	"fmt"
+	"net/http"
+	"runtime"
+	"sync/atomic"
+	"time"
+)
+
+// CountingHandler counts the records passing through it by level before delegating them,
+// unchanged, to the wrapped handler
+type CountingHandler struct {
+	inner  Handler
+	counts [LevelPanic - LevelTrace + 1]atomic.Uint64
+}
+
+func NewCountingHandler(inner Handler) *CountingHandler {
+	return &CountingHandler{inner: inner}
+}
+
+// Counts returns the number of records received for each level
+func (handler *CountingHandler) Counts() map[Level]uint64 {
+	counts := make(map[Level]uint64, len(handler.counts))
+	for i := range handler.counts {
+		counts[LevelTrace+Level(i)] = handler.counts[i].Load()
+	}
+
+	return counts
+}
+
+// ServeHTTP exposes the counts in the Prometheus text format as log_records_total
+func (handler *CountingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+
+	fmt.Fprintln(w, "# HELP log_records_total Number of log records emitted by level.")
+	fmt.Fprintln(w, "# TYPE log_records_total counter")
+	for i := range handler.counts {
+		fmt.Fprintf(w, "log_records_total{level=%q} %d\n", (LevelTrace + Level(i)).String(), handler.counts[i].Load())
+	}
+}
+
+// Implements [logging.LeveledHandler]
+func (handler *CountingHandler) Level() Level {
+	return handlerLevel(handler.inner)
+}
+
+// Implements [logging.Handler]
+func (handler *CountingHandler) OnLoggerCreated(logger *Logger, timestamp time.Time, caller *runtime.Frame) {
+	handler.inner.OnLoggerCreated(logger, timestamp, caller)
+}
+
+// Implements [logging.Handler]
+func (handler *CountingHandler) OnLoggerClosed(logger *Logger, timestamp time.Time, caller *runtime.Frame) error {
+	return handler.inner.OnLoggerClosed(logger, timestamp, caller)
+}
+
+// Implements [logging.Handler]
+func (handler *CountingHandler) HandleRecord(logger *Logger, record Record) error {
+	if i := int(record.Level - LevelTrace); i >= 0 && i < len(handler.counts) {
+		handler.counts[i].Add(1)
+	}
+
+	return handler.inner.HandleRecord(logger, record)
+}
@@ -0,0 +1,168 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"time"
+)
+
+const datedDirectoryLayout = "2006-01-02"
+
+// DatedDirectoryHandler writes to a file in a directory named after the current date, ex.
+// logs/2024-06-01/app.ndjson, switching to a new directory at midnight. The date is taken
+// from the timestamp of each record, so the switch happens with the first record of the new
+// day. Records stamped slightly before midnight that arrive after the switch are written to
+// the new file rather than reopening the previous one.
+type DatedDirectoryHandler struct {
+	mutex sync.Mutex
+
+	root      string
+	fileName  string
+	location  *time.Location
+	level     Level
+	formatter Formatter
+
+	date  string
+	file  *os.File
+	inner Handler
+}
+
+// NewDatedDirectoryHandler creates a handler writing records rendered by formatter, or
+// [JsonFormatter] if formatter is nil, to root/<date>/fileName. Dates are computed in
+// location, or UTC if location is nil. Directories and files are created on first use.
+func NewDatedDirectoryHandler(root string, fileName string, location *time.Location, level Level, formatter Formatter) *DatedDirectoryHandler {
+	if location == nil {
+		location = time.UTC
+	}
+
+	if formatter == nil {
+		formatter = JsonFormatter
+	}
+
+	return &DatedDirectoryHandler{
+		root:      root,
+		fileName:  fileName,
+		location:  location,
+		level:     level,
+		formatter: formatter,
+	}
+}
+
+// handlerFor returns the handler for the file of timestamp, opening it and closing the
+// previous file if the date moved forward. The caller must hold the mutex.
+func (handler *DatedDirectoryHandler) handlerFor(timestamp time.Time) (Handler, error) {
+	date := timestamp.In(handler.location).Format(datedDirectoryLayout)
+	if handler.inner != nil && date <= handler.date {
+		return handler.inner, nil
+	}
+
+	dir := filepath.Join(handler.root, date)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(filepath.Join(dir, handler.fileName), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	closeErr := handler.closeFile()
+
+	handler.date = date
+	handler.file = file
+	handler.inner = handler.formatter(file)
+
+	return handler.inner, closeErr
+}
+
+// closeFile flushes and closes the current file. The caller must hold the mutex.
+func (handler *DatedDirectoryHandler) closeFile() error {
+	if handler.file == nil {
+		return nil
+	}
+
+	var flushErr error
+	if flusher, ok := handler.inner.(Flusher); ok {
+		flushErr = flusher.Flush()
+	}
+
+	err := handler.file.Close()
+	handler.file = nil
+	handler.inner = nil
+
+	return errors.Join(flushErr, err)
+}
+
+// Implements [io.Closer]
+func (handler *DatedDirectoryHandler) Close() error {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	return handler.closeFile()
+}
+
+// Implements [logging.ContextCloser], so that the current file is closed with the logger
+func (handler *DatedDirectoryHandler) CloseContext(ctx context.Context) error {
+	return handler.Close()
+}
+
+// Implements [logging.Flusher]
+func (handler *DatedDirectoryHandler) Flush() error {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	if flusher, ok := handler.inner.(Flusher); ok {
+		return flusher.Flush()
+	}
+
+	return nil
+}
+
+// Implements [logging.LeveledHandler]
+func (handler *DatedDirectoryHandler) Level() Level {
+	return handler.level
+}
+
+// Implements [logging.Handler]
+func (handler *DatedDirectoryHandler) OnLoggerCreated(logger *Logger, timestamp time.Time, caller *runtime.Frame) {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	if inner, err := handler.handlerFor(timestamp); inner != nil && err == nil {
+		inner.OnLoggerCreated(logger, timestamp, caller)
+	}
+}
+
+// Implements [logging.Handler]
+func (handler *DatedDirectoryHandler) OnLoggerClosed(logger *Logger, timestamp time.Time, caller *runtime.Frame) error {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	inner, err := handler.handlerFor(timestamp)
+	if inner == nil {
+		return err
+	}
+
+	return errors.Join(err, inner.OnLoggerClosed(logger, timestamp, caller))
+}
+
+// Implements [logging.Handler]
+func (handler *DatedDirectoryHandler) HandleRecord(logger *Logger, record Record) error {
+	if record.Level < handler.level {
+		return nil
+	}
+
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	inner, err := handler.handlerFor(record.Time)
+	if inner == nil {
+		return err
+	}
+
+	return errors.Join(err, inner.HandleRecord(logger, record))
+}
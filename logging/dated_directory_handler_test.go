@@ -0,0 +1,38 @@
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDatedDirectoryHandlerClosedWithLogger(t *testing.T) {
+	root := t.TempDir()
+	handler := NewDatedDirectoryHandler(root, "app.ndjson", nil, LevelTrace, nil)
+
+	logger := NewLogger()
+	logger.AddHandler(handler)
+
+	logger.Info("message")
+
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if handler.file != nil {
+		t.Error("the current file is still open after the logger was closed")
+	}
+
+	path := filepath.Join(root, time.Now().UTC().Format(datedDirectoryLayout), "app.ndjson")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The record and the closed message are both written before the file is closed
+	if lines := strings.Split(strings.TrimSpace(string(data)), "\n"); len(lines) != 2 {
+		t.Errorf("got %d lines, want 2: %q", len(lines), data)
+	}
+}
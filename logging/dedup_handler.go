@@ -0,0 +1,185 @@
+package logging
+
+import (
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DedupMode selects which parts of records must match for them to be considered repeats.
+// The level and the logger always have to match.
+type DedupMode int
+
+const (
+	// Records repeat when their messages and attributes match
+	DedupMode_MessageAttrs DedupMode = iota
+	// Records repeat when their messages match, regardless of their attributes
+	DedupMode_Message
+)
+
+// DedupHandler suppresses consecutive repeats of a record before they reach the wrapped
+// handler. The first record is passed on immediately. Repeats within the window are dropped,
+// and once a different record arrives or the window elapses, the last repeat is passed on
+// with a "repeated" attribute holding the number of dropped records.
+type DedupHandler struct {
+	mutex sync.Mutex
+
+	inner  Handler
+	window time.Duration
+	mode   DedupMode
+
+	key     string
+	logger  *Logger
+	start   time.Time
+	last    Record
+	repeats int
+
+	// Incremented when a run ends, so that the timer of an earlier run does nothing
+	run   uint64
+	timer *time.Timer
+}
+
+func NewDedupHandler(inner Handler, window time.Duration, mode DedupMode) *DedupHandler {
+	return &DedupHandler{inner: inner, window: window, mode: mode}
+}
+
+// dedupKey identifies record, whose attributes must already be resolved, for comparing it
+// with the previous one
+func (handler *DedupHandler) dedupKey(record Record) string {
+	var key strings.Builder
+	key.WriteString(record.Level.String())
+	key.WriteString("\x00")
+	key.WriteString(record.Message)
+
+	if handler.mode == DedupMode_MessageAttrs {
+		for _, attr := range record.Attributes {
+			key.WriteString("\x00")
+			key.WriteString(attr.String())
+		}
+	}
+
+	return key.String()
+}
+
+// emitRepeats passes on the last repeat of the current run, if any, and ends the run. The
+// caller must hold the mutex.
+func (handler *DedupHandler) emitRepeats() error {
+	if handler.timer != nil {
+		handler.timer.Stop()
+		handler.timer = nil
+	}
+
+	handler.run++
+
+	logger, record, repeats := handler.logger, handler.last, handler.repeats
+	handler.key, handler.logger, handler.last, handler.repeats = "", nil, Record{}, 0
+
+	if repeats == 0 {
+		return nil
+	}
+
+	record.Attributes = append(slices.Clip(record.Attributes), Attribute{Key: "repeated", Value: repeats})
+	return handler.inner.HandleRecord(logger, record)
+}
+
+// expire ends the run when the window elapses, reporting errors of the wrapped handler to
+// the error handler of the logger since there is no caller to return them to
+func (handler *DedupHandler) expire(run uint64) {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	// The run already ended and a new one may have started
+	if handler.run != run {
+		return
+	}
+
+	logger := handler.logger
+	if err := handler.emitRepeats(); err != nil {
+		logger.reportHandlerError(handler, err)
+	}
+}
+
+// Implements [logging.Flusher]
+func (handler *DedupHandler) Flush() error {
+	handler.mutex.Lock()
+	err := handler.emitRepeats()
+	handler.mutex.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	if flusher, ok := handler.inner.(Flusher); ok {
+		return flusher.Flush()
+	}
+
+	return nil
+}
+
+// Implements [logging.LeveledHandler]
+func (handler *DedupHandler) Level() Level {
+	return handlerLevel(handler.inner)
+}
+
+// Implements [logging.Handler]
+func (handler *DedupHandler) OnLoggerCreated(logger *Logger, timestamp time.Time, caller *runtime.Frame) {
+	handler.inner.OnLoggerCreated(logger, timestamp, caller)
+}
+
+// Implements [logging.Handler]
+func (handler *DedupHandler) OnLoggerClosed(logger *Logger, timestamp time.Time, caller *runtime.Frame) error {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	// Repeats are written before the logger is reported as closed
+	var err error
+	if handler.logger == logger {
+		err = handler.emitRepeats()
+	}
+
+	if closeErr := handler.inner.OnLoggerClosed(logger, timestamp, caller); closeErr != nil {
+		return closeErr
+	}
+
+	return err
+}
+
+// Implements [logging.Handler]
+func (handler *DedupHandler) HandleRecord(logger *Logger, record Record) error {
+	// Records the wrapped handler drops are neither compared nor evaluated
+	if record.Level < handlerLevel(handler.inner) {
+		return nil
+	}
+
+	// Lazy values are evaluated once for the key, and the wrapped handler receives the
+	// results instead of evaluating them again
+	record.Attributes = resolveAttrs(record.Attributes)
+	key := handler.dedupKey(record)
+
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	now := time.Now()
+	if handler.logger == logger && handler.key == key && now.Sub(handler.start) < handler.window {
+		handler.last = record
+		handler.repeats++
+
+		if handler.timer == nil {
+			run := handler.run
+			handler.timer = time.AfterFunc(handler.window-now.Sub(handler.start), func() { handler.expire(run) })
+		}
+
+		return nil
+	}
+
+	err := handler.emitRepeats()
+
+	handler.key, handler.logger, handler.start = key, logger, now
+	if recordErr := handler.inner.HandleRecord(logger, record); recordErr != nil {
+		return recordErr
+	}
+
+	return err
+}
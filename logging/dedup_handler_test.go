@@ -0,0 +1,55 @@
+package logging
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDedupHandlerEvaluatesLazyValuesOnce(t *testing.T) {
+	var handled []Record
+	inner := NewFuncHandler(LevelInfo, func(logger *Logger, record Record) error {
+		// Evaluates the attributes like any handler emitting the record
+		resolveAttrs(record.Attributes)
+		handled = append(handled, record)
+		return nil
+	})
+
+	handler := NewDedupHandler(inner, time.Hour, DedupMode_MessageAttrs)
+	logger := NewLogger()
+
+	evaluations := 0
+	lazy := LazyValue(func() any {
+		evaluations++
+		return "value"
+	})
+
+	record := Record{Level: LevelInfo, Message: "message", Attributes: []Attribute{{Key: "lazy", Value: lazy}}}
+	for range 3 {
+		if err := handler.HandleRecord(logger, record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := handler.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(handled) != 2 {
+		t.Fatalf("got %d records, want the first one and the last repeat", len(handled))
+	}
+
+	if evaluations != 3 {
+		t.Errorf("lazy value was evaluated %d times for 3 records, want 3", evaluations)
+	}
+
+	// Records below the level of the wrapped handler are dropped without being evaluated
+	evaluations = 0
+	record.Level = LevelDebug
+	if err := handler.HandleRecord(logger, record); err != nil {
+		t.Fatal(err)
+	}
+
+	if evaluations != 0 {
+		t.Errorf("lazy value of a dropped record was evaluated %d times", evaluations)
+	}
+}
@@ -0,0 +1,33 @@
+package logging
+
+import (
+	"runtime"
+	"time"
+)
+
+// DiscardHandler drops everything it receives
+type DiscardHandler struct{}
+
+func NewDiscardHandler() DiscardHandler {
+	return DiscardHandler{}
+}
+
+// Implements [logging.LeveledHandler]. The level is above every record level, so that
+// [Logger.Enabled] is false for loggers that only discard.
+func (handler DiscardHandler) Level() Level {
+	return LevelPanic + 1
+}
+
+// Implements [logging.Handler]
+func (handler DiscardHandler) OnLoggerCreated(logger *Logger, timestamp time.Time, caller *runtime.Frame) {
+}
+
+// Implements [logging.Handler]
+func (handler DiscardHandler) OnLoggerClosed(logger *Logger, timestamp time.Time, caller *runtime.Frame) error {
+	return nil
+}
+
+// Implements [logging.Handler]
+func (handler DiscardHandler) HandleRecord(logger *Logger, record Record) error {
+	return nil
+}
@@ -0,0 +1,31 @@
+package logging
+
+import "testing"
+
+func TestNopLoggerDisabled(t *testing.T) {
+	logger := NewNopLogger()
+
+	for _, level := range []Level{LevelTrace, LevelDebug, LevelInfo, LevelWarn, LevelError, LevelFatal, LevelPanic} {
+		if logger.Enabled(level) {
+			t.Errorf("Enabled(%v) is true for a nop logger", level)
+		}
+	}
+
+	// A discarding handler does not hide the levels of the other handlers
+	logger.AddHandler(NewFuncHandler(LevelWarn, func(logger *Logger, record Record) error { return nil }))
+	if !logger.Enabled(LevelWarn) || logger.Enabled(LevelInfo) {
+		t.Errorf("levels of the other handlers are not honored")
+	}
+}
+
+func TestNopLoggerEventDoesNotAllocate(t *testing.T) {
+	logger := NewNopLogger()
+
+	allocs := testing.AllocsPerRun(100, func() {
+		logger.With(LevelDebug).String("key", "value").Int("n", 1).Msg("message")
+	})
+
+	if allocs != 0 {
+		t.Errorf("got %v allocations, want 0", allocs)
+	}
+}
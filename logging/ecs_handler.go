@@ -0,0 +1,147 @@
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Version of the Elastic Common Schema the documents conform to
+const EcsVersion = "8.11.0"
+
+// EcsHandler writes records as newline delimited JSON documents following the Elastic Common
+// Schema, so that they can be ingested into Elasticsearch without a transform. Attributes
+// are written under labels as strings, with groups flattened into keys joined with
+// underscores, and the first error is mapped to the error fields. Logger lifecycle events
+// are not written.
+type EcsHandler struct {
+	writer io.Writer
+	level  Level
+}
+
+func NewEcsHandler(writer io.Writer, level Level) EcsHandler {
+	return EcsHandler{writer: writer, level: level}
+}
+
+// EcsLevel returns the log.level written for level, using the syslog severity names for the
+// levels that have no common name
+func EcsLevel(level Level) string {
+	switch level {
+	case LevelFatal:
+		return "critical"
+	case LevelPanic:
+		return "emergency"
+	default:
+		return level.String()
+	}
+}
+
+// Implements [logging.LeveledHandler]
+func (handler EcsHandler) Level() Level {
+	return handler.level
+}
+
+// Implements [logging.Handler]
+func (handler EcsHandler) OnLoggerCreated(logger *Logger, timestamp time.Time, caller *runtime.Frame) {
+}
+
+// Implements [logging.Handler]
+func (handler EcsHandler) OnLoggerClosed(logger *Logger, timestamp time.Time, caller *runtime.Frame) error {
+	return nil
+}
+
+// Implements [logging.Handler]
+func (handler EcsHandler) HandleRecord(logger *Logger, record Record) error {
+	if record.Level < handler.level {
+		return nil
+	}
+
+	attrs := resolveAttrs(record.Attributes)
+
+	doc := JsonHandlerAttributes{
+		{Key: "@timestamp", Value: record.Time.UTC().Format(time.RFC3339Nano)},
+		{Key: "log.level", Value: EcsLevel(record.Level)},
+		{Key: "log.syslog.severity.code", Value: syslogSeverity(record.Level)},
+		{Key: "message", Value: record.Message},
+		{Key: "ecs.version", Value: EcsVersion},
+		{Key: "log.logger", Value: logger.id.String()},
+	}
+
+	if record.Caller != nil {
+		doc = append(doc,
+			JsonHandlerAttribute{Key: "log.origin.file.name", Value: record.Caller.File},
+			JsonHandlerAttribute{Key: "log.origin.file.line", Value: record.Caller.Line},
+			JsonHandlerAttribute{Key: "log.origin.function", Value: record.Caller.Function},
+		)
+	}
+
+	if record.TraceId != "" {
+		doc = append(doc, JsonHandlerAttribute{Key: "trace.id", Value: record.TraceId})
+	}
+
+	if record.SpanId != "" {
+		doc = append(doc, JsonHandlerAttribute{Key: "span.id", Value: record.SpanId})
+	}
+
+	emitHostname, emitPid := logger.emitsProcess()
+	if emitHostname {
+		if hostname, ok := takeLastAttr(&attrs, "hostname"); ok {
+			doc = append(doc, JsonHandlerAttribute{Key: "host.hostname", Value: hostname})
+		}
+	}
+
+	if emitPid {
+		if pid, ok := takeLastAttr(&attrs, "pid"); ok {
+			doc = append(doc, JsonHandlerAttribute{Key: "process.pid", Value: pid})
+		}
+	}
+
+	if err, ok := firstError(attrs); ok {
+		doc = append(doc, JsonHandlerAttribute{Key: "error.message", Value: err.Error()})
+		doc = append(doc, JsonHandlerAttribute{Key: "error.type", Value: fmt.Sprintf("%T", err)})
+
+		if stack, ok := errorStack(err); ok {
+			doc = append(doc, JsonHandlerAttribute{Key: "error.stack_trace", Value: stack})
+		}
+	}
+
+	if labels := ecsLabels(make(JsonHandlerAttributes, 0, len(attrs)), "", attrs); len(labels) > 0 {
+		doc = append(doc, JsonHandlerAttribute{Key: "labels", Value: labels})
+	}
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	return writeRecord(handler.writer, append(data, '\n'))
+}
+
+// ecsLabels adds attrs to labels, which ECS defines as a flat object of keyword values. Groups
+// are flattened into keys joined with underscores, since dots in label keys would be expanded
+// into objects by Elasticsearch, and values are written as strings.
+func ecsLabels(labels JsonHandlerAttributes, prefix string, attrs []Attribute) JsonHandlerAttributes {
+	for _, attr := range attrs {
+		key := prefix + strings.ReplaceAll(attr.Key, ".", "_")
+
+		switch v := attr.Value.(type) {
+		case []Attribute:
+			labels = ecsLabels(labels, key+"_", v)
+		case string:
+			labels = labels.set(key, v)
+		case error:
+			labels = labels.set(key, v.Error())
+		case time.Time:
+			labels = labels.set(key, v.Format(time.RFC3339Nano))
+		case nil:
+			labels = labels.set(key, "")
+		default:
+			labels = labels.set(key, fmt.Sprintf("%v", v))
+		}
+	}
+
+	return labels
+}
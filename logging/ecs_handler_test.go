@@ -0,0 +1,60 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestEcsHandlerLabels(t *testing.T) {
+	var buf bytes.Buffer
+	handler := NewEcsHandler(&buf, LevelTrace)
+
+	record := Record{
+		Time:    time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Level:   LevelInfo,
+		Message: "request",
+		Attributes: []Attribute{
+			String("method", "GET"),
+			Int("status", 200),
+			Bool("cached", false),
+			Duration("elapsed", 1500*time.Millisecond),
+			Group("user", "id", 7, Group("org", "name", "acme")),
+			{Key: "a.b", Value: 1.5},
+			{Key: "err", Value: errors.New("failed")},
+			{Key: "none", Value: nil},
+		},
+	}
+
+	if err := handler.HandleRecord(NewLogger(), record); err != nil {
+		t.Fatal(err)
+	}
+
+	var doc struct {
+		Labels map[string]any `json:"labels"`
+	}
+
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid document %q: %v", buf.String(), err)
+	}
+
+	// Labels are a flat object of strings
+	want := map[string]any{
+		"method":        "GET",
+		"status":        "200",
+		"cached":        "false",
+		"elapsed":       "1.5s",
+		"user_id":       "7",
+		"user_org_name": "acme",
+		"a_b":           "1.5",
+		"err":           "failed",
+		"none":          "",
+	}
+
+	if !reflect.DeepEqual(doc.Labels, want) {
+		t.Errorf("got labels %v, want %v", doc.Labels, want)
+	}
+}
@@ -0,0 +1,111 @@
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+)
+
+// FieldsError is implemented by errors that carry structured context, which
+// [Logger.LogError] logs as attributes
+type FieldsError interface {
+	error
+	Fields() map[string]any
+}
+
+// errorAttrs describes err as attributes: the error itself, its type, its innermost cause
+// and the fields of the first error in its chain implementing [FieldsError]
+func errorAttrs(err error) []Attribute {
+	attrs := []Attribute{
+		{Key: "error", Value: err},
+		{Key: "errorType", Value: fmt.Sprintf("%T", err)},
+	}
+
+	if chain := errorChain(err); len(chain) > 0 {
+		attrs = append(attrs, Attribute{Key: "cause", Value: chain[len(chain)-1]})
+	}
+
+	var fieldsErr FieldsError
+	if errors.As(err, &fieldsErr) {
+		fields := fieldsErr.Fields()
+
+		keys := make([]string, 0, len(fields))
+		for key := range fields {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+
+		group := make([]Attribute, len(keys))
+		for i, key := range keys {
+			group[i] = Attribute{Key: key, Value: fields[key]}
+		}
+
+		attrs = append(attrs, Attribute{Key: "fields", Value: group})
+	}
+
+	return attrs
+}
+
+// errorChain returns the messages of every error wrapped by err, following [errors.Unwrap]
+func errorChain(err error) []string {
+	chain := make([]string, 0)
+
+	for cause := errors.Unwrap(err); cause != nil; cause = errors.Unwrap(cause) {
+		chain = append(chain, cause.Error())
+	}
+
+	return chain
+}
+
+// errorStack returns the stack trace of errors that print one when formatted with %+v,
+// such as those created by github.com/pkg/errors
+func errorStack(err error) (string, bool) {
+	if _, ok := err.(fmt.Formatter); !ok {
+		return "", false
+	}
+
+	stack := fmt.Sprintf("%+v", err)
+	if stack == err.Error() {
+		return "", false
+	}
+
+	return stack, true
+}
+
+// errorDetails returns the wrapped error chain and stack trace of err as attributes
+func errorDetails(err error) []Attribute {
+	details := make([]Attribute, 0)
+
+	chain := errorChain(err)
+	if len(chain) > 0 {
+		causes := make([]Attribute, len(chain))
+		for i, cause := range chain {
+			causes[i] = Attribute{Key: strconv.Itoa(i), Value: cause}
+		}
+
+		details = append(details, Attribute{Key: "chain", Value: causes})
+	}
+
+	if stack, ok := errorStack(err); ok {
+		details = append(details, Attribute{Key: "stack", Value: stack})
+	}
+
+	return details
+}
+
+// firstError returns the first attribute value implementing error, searching groups depth first
+func firstError(attrs []Attribute) (error, bool) {
+	for _, attr := range attrs {
+		switch v := attr.Value.(type) {
+		case error:
+			return v, true
+		case []Attribute:
+			if err, ok := firstError(v); ok {
+				return err, true
+			}
+		}
+	}
+
+	return nil, false
+}
@@ -0,0 +1,120 @@
+package logging
+
+import (
+	"time"
+)
+
+// Event builds a record one attribute at a time. It is created by [Logger.With], which
+// returns nil when the level is disabled. Every method of a nil event does nothing, so the
+// attributes of a disabled record are never constructed and nothing is allocated:
+//
+//	logger.With(logging.LevelDebug).String("query", query).Int("rows", rows).Msg("query done")
+type Event struct {
+	logger *Logger
+	level  Level
+	attrs  []Attribute
+}
+
+// With starts an [Event] at level, or returns nil if no handler would accept a record at
+// level, see [Logger.Enabled]
+func (logger *Logger) With(level Level) *Event {
+	if !logger.Enabled(level) {
+		return nil
+	}
+
+	return &Event{logger: logger, level: level}
+}
+
+// Enabled reports whether the event will be logged
+func (event *Event) Enabled() bool {
+	return event != nil
+}
+
+// Attr adds an attribute with any value
+func (event *Event) Attr(key string, value any) *Event {
+	if event == nil {
+		return nil
+	}
+
+	event.attrs = append(event.attrs, Attribute{Key: key, Value: value})
+	return event
+}
+
+func (event *Event) String(key string, value string) *Event {
+	if event == nil {
+		return nil
+	}
+
+	return event.Attr(key, value)
+}
+
+func (event *Event) Int(key string, value int) *Event {
+	if event == nil {
+		return nil
+	}
+
+	return event.Attr(key, value)
+}
+
+func (event *Event) Int64(key string, value int64) *Event {
+	if event == nil {
+		return nil
+	}
+
+	return event.Attr(key, value)
+}
+
+func (event *Event) Bool(key string, value bool) *Event {
+	if event == nil {
+		return nil
+	}
+
+	return event.Attr(key, value)
+}
+
+func (event *Event) Float64(key string, value float64) *Event {
+	if event == nil {
+		return nil
+	}
+
+	return event.Attr(key, value)
+}
+
+func (event *Event) Duration(key string, value time.Duration) *Event {
+	if event == nil {
+		return nil
+	}
+
+	return event.Attr(key, value)
+}
+
+func (event *Event) Time(key string, value time.Time) *Event {
+	if event == nil {
+		return nil
+	}
+
+	return event.Attr(key, value)
+}
+
+// Err adds err as the "error" attribute
+func (event *Event) Err(err error) *Event {
+	if event == nil {
+		return nil
+	}
+
+	return event.Attr("error", err)
+}
+
+// Msg logs the event with message. The event must not be used afterwards.
+func (event *Event) Msg(message string) error {
+	if event == nil {
+		return nil
+	}
+
+	err := event.logger.logAttrs(RecordKind_Log, time.Time{}, event.level, message, event.attrs)
+	if err != nil && event.logger.PanicOnError() {
+		panic(err)
+	}
+
+	return err
+}
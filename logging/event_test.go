@@ -0,0 +1,67 @@
+package logging
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// newEventLogger creates a logger accepting records at LevelInfo and above
+func newEventLogger(fn func(logger *Logger, record Record) error) *Logger {
+	logger := NewLogger()
+	logger.AddHandler(NewFuncHandler(LevelInfo, fn))
+
+	return logger
+}
+
+func TestEventDisabledDoesNotAllocate(t *testing.T) {
+	logger := newEventLogger(func(logger *Logger, record Record) error {
+		t.Error("disabled event was logged")
+		return nil
+	})
+
+	query, rows, err := "SELECT 1", 42, errors.New("failed")
+
+	allocs := testing.AllocsPerRun(100, func() {
+		logger.With(LevelDebug).
+			String("query", query).
+			Int("rows", rows).
+			Duration("elapsed", time.Second).
+			Err(err).
+			Msg("query done")
+	})
+
+	if allocs != 0 {
+		t.Errorf("got %v allocations, want 0", allocs)
+	}
+}
+
+func TestEventEnabled(t *testing.T) {
+	var got Record
+	logger := newEventLogger(func(logger *Logger, record Record) error {
+		got = record
+		return nil
+	})
+
+	if err := logger.With(LevelInfo).String("query", "SELECT 1").Int("rows", 42).Msg("query done"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Message != "query done" || got.Level != LevelInfo {
+		t.Errorf("got record %+v", got)
+	}
+
+	if rows, _ := got.AttrValue("rows"); rows != 42 {
+		t.Errorf("got rows %v, want 42", rows)
+	}
+}
+
+func BenchmarkEventDisabled(b *testing.B) {
+	logger := newEventLogger(func(logger *Logger, record Record) error { return nil })
+	query := "SELECT 1"
+
+	b.ReportAllocs()
+	for range b.N {
+		logger.With(LevelDebug).String("query", query).Int("rows", 42).Msg("query done")
+	}
+}
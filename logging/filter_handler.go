@@ -0,0 +1,57 @@
+package logging
+
+import (
+	"runtime"
+	"strings"
+	"time"
+)
+
+// FilterHandler delegates only the records matching a predicate to the wrapped handler.
+// Logger lifecycle events are always delegated.
+type FilterHandler struct {
+	inner     Handler
+	predicate func(record Record) bool
+}
+
+func NewFilterHandler(inner Handler, predicate func(record Record) bool) *FilterHandler {
+	return &FilterHandler{inner: inner, predicate: predicate}
+}
+
+// CallerModuleIs returns a predicate matching records logged from module or any package
+// below it, ex. "myapp/db" matches "myapp/db" and "myapp/db/migrations" but not "myapp/dbx"
+func CallerModuleIs(module string) func(record Record) bool {
+	return func(record Record) bool {
+		return record.CallerModule == module || strings.HasPrefix(record.CallerModule, module+"/")
+	}
+}
+
+// RecordKindIs returns a predicate matching records of kind
+func RecordKindIs(kind RecordKind) func(record Record) bool {
+	return func(record Record) bool {
+		return record.Kind == kind
+	}
+}
+
+// Implements [logging.LeveledHandler]
+func (handler *FilterHandler) Level() Level {
+	return handlerLevel(handler.inner)
+}
+
+// Implements [logging.Handler]
+func (handler *FilterHandler) OnLoggerCreated(logger *Logger, timestamp time.Time, caller *runtime.Frame) {
+	handler.inner.OnLoggerCreated(logger, timestamp, caller)
+}
+
+// Implements [logging.Handler]
+func (handler *FilterHandler) OnLoggerClosed(logger *Logger, timestamp time.Time, caller *runtime.Frame) error {
+	return handler.inner.OnLoggerClosed(logger, timestamp, caller)
+}
+
+// Implements [logging.Handler]
+func (handler *FilterHandler) HandleRecord(logger *Logger, record Record) error {
+	if !handler.predicate(record) {
+		return nil
+	}
+
+	return handler.inner.HandleRecord(logger, record)
+}
@@ -0,0 +1,69 @@
+package logging
+
+import (
+	"runtime"
+	"slices"
+	"time"
+)
+
+// ForwardingHandler re-emits records onto another logger, which applies its own settings and
+// handlers. Records logged to the target or its descendants already reach its handlers and
+// are not forwarded again. Records that have already passed through the handler, such as
+// when two loggers forward to each other, are dropped instead of looping.
+type ForwardingHandler struct {
+	target *Logger
+
+	prefix    string
+	sourceKey string
+	source    any
+}
+
+func NewForwardingHandler(target *Logger) *ForwardingHandler {
+	return &ForwardingHandler{target: target}
+}
+
+// WithPrefix prepends prefix to the message of forwarded records and returns the handler. It
+// should be set before the handler is added to a logger.
+func (handler *ForwardingHandler) WithPrefix(prefix string) *ForwardingHandler {
+	handler.prefix = prefix
+	return handler
+}
+
+// WithSource adds an attribute identifying the source of forwarded records and returns the
+// handler. It should be set before the handler is added to a logger.
+func (handler *ForwardingHandler) WithSource(key string, value any) *ForwardingHandler {
+	handler.sourceKey = key
+	handler.source = value
+	return handler
+}
+
+// Implements [logging.Handler]
+func (handler *ForwardingHandler) OnLoggerCreated(logger *Logger, timestamp time.Time, caller *runtime.Frame) {
+}
+
+// Implements [logging.Handler]
+func (handler *ForwardingHandler) OnLoggerClosed(logger *Logger, timestamp time.Time, caller *runtime.Frame) error {
+	return nil
+}
+
+// Implements [logging.Handler]
+func (handler *ForwardingHandler) HandleRecord(logger *Logger, record Record) error {
+	if slices.Contains(record.forwardedBy, handler) {
+		return nil
+	}
+
+	for l := logger; l != nil; l = l.parent {
+		if l == handler.target {
+			return nil
+		}
+	}
+
+	record.forwardedBy = append(slices.Clip(record.forwardedBy), handler)
+	record.Message = handler.prefix + record.Message
+
+	if handler.sourceKey != "" {
+		record.Attributes = append(slices.Clip(record.Attributes), Attribute{Key: handler.sourceKey, Value: handler.source})
+	}
+
+	return handler.target.LogBatch([]Record{record})
+}
@@ -0,0 +1,65 @@
+package logging
+
+import (
+	"runtime"
+	"time"
+)
+
+// FuncHandler adapts a function to a [Handler], like [net/http.HandlerFunc]. Logger
+// lifecycle events are ignored unless callbacks are set for them.
+type FuncHandler struct {
+	level Level
+	fn    func(logger *Logger, record Record) error
+
+	onCreated func(logger *Logger, timestamp time.Time, caller *runtime.Frame)
+	onClosed  func(logger *Logger, timestamp time.Time, caller *runtime.Frame) error
+}
+
+// NewFuncHandler creates a handler calling fn with every record at level or above
+func NewFuncHandler(level Level, fn func(logger *Logger, record Record) error) *FuncHandler {
+	return &FuncHandler{level: level, fn: fn}
+}
+
+// OnCreated sets the callback for [Handler.OnLoggerCreated] and returns the handler. It
+// should be set before the handler is added to a logger.
+func (handler *FuncHandler) OnCreated(fn func(logger *Logger, timestamp time.Time, caller *runtime.Frame)) *FuncHandler {
+	handler.onCreated = fn
+	return handler
+}
+
+// OnClosed sets the callback for [Handler.OnLoggerClosed] and returns the handler. It should
+// be set before the handler is added to a logger.
+func (handler *FuncHandler) OnClosed(fn func(logger *Logger, timestamp time.Time, caller *runtime.Frame) error) *FuncHandler {
+	handler.onClosed = fn
+	return handler
+}
+
+// Implements [logging.LeveledHandler]
+func (handler *FuncHandler) Level() Level {
+	return handler.level
+}
+
+// Implements [logging.Handler]
+func (handler *FuncHandler) OnLoggerCreated(logger *Logger, timestamp time.Time, caller *runtime.Frame) {
+	if handler.onCreated != nil {
+		handler.onCreated(logger, timestamp, caller)
+	}
+}
+
+// Implements [logging.Handler]
+func (handler *FuncHandler) OnLoggerClosed(logger *Logger, timestamp time.Time, caller *runtime.Frame) error {
+	if handler.onClosed != nil {
+		return handler.onClosed(logger, timestamp, caller)
+	}
+
+	return nil
+}
+
+// Implements [logging.Handler]
+func (handler *FuncHandler) HandleRecord(logger *Logger, record Record) error {
+	if record.Level < handler.level {
+		return nil
+	}
+
+	return handler.fn(logger, record)
+}
@@ -0,0 +1,264 @@
+package logging
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"regexp"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// Chunk size recommended by Graylog for sending over the internet, which fits in the MTU of
+// most paths
+const GelfDefaultChunkSize = 1420
+
+// GELF allows at most 128 chunks of a message
+const gelfMaxChunks = 128
+
+var gelfChunkMagic = []byte{0x1e, 0x0f}
+
+var ErrGelfMessageTooLarge = errors.New("GELF message too large")
+
+// Characters not allowed in the names of additional fields
+var gelfInvalidFieldChars = regexp.MustCompile(`[^\w.\-]`)
+
+// GelfHandler sends records to a Graylog server as GELF 1.1 messages over UDP. Attributes
+// are sent as additional fields, with groups flattened into dotted names. Messages larger
+// than the chunk size are split into GELF chunks.
+type GelfHandler struct {
+	mutex sync.Mutex
+
+	address   string
+	level     Level
+	compress  bool
+	chunkSize int
+	hostname  string
+
+	conn net.Conn
+}
+
+// NewGelfHandler creates a handler sending to address over UDP, compressing messages with
+// gzip if compress is true. The connection is established on the first record.
+func NewGelfHandler(address string, compress bool, level Level) *GelfHandler {
+	hostname := processHostname()
+	if hostname == "" {
+		hostname = "unknown"
+	}
+
+	return &GelfHandler{
+		address:   address,
+		level:     level,
+		compress:  compress,
+		chunkSize: GelfDefaultChunkSize,
+		hostname:  hostname,
+	}
+}
+
+// SetChunkSize sets the maximum size of the datagrams sent, including the chunk header.
+// Messages split into more than 128 chunks are dropped with [ErrGelfMessageTooLarge].
+func (handler *GelfHandler) SetChunkSize(size int) {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	handler.chunkSize = size
+}
+
+// Implements [io.Closer]
+func (handler *GelfHandler) Close() error {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	if handler.conn == nil {
+		return nil
+	}
+
+	err := handler.conn.Close()
+	handler.conn = nil
+
+	return err
+}
+
+// Implements [logging.ContextCloser]
+func (handler *GelfHandler) CloseContext(ctx context.Context) error {
+	done := make(chan error, 1)
+	go func() { done <- handler.Close() }()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Implements [logging.LeveledHandler]
+func (handler *GelfHandler) Level() Level {
+	return handler.level
+}
+
+// Implements [logging.Handler]
+func (handler *GelfHandler) OnLoggerCreated(logger *Logger, timestamp time.Time, caller *runtime.Frame) {
+}
+
+// Implements [logging.Handler]
+func (handler *GelfHandler) OnLoggerClosed(logger *Logger, timestamp time.Time, caller *runtime.Frame) error {
+	return nil
+}
+
+// Implements [logging.Handler]
+func (handler *GelfHandler) HandleRecord(logger *Logger, record Record) error {
+	if record.Level < handler.level {
+		return nil
+	}
+
+	payload, err := handler.encode(logger, record)
+	if err != nil {
+		return err
+	}
+
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	if handler.conn == nil {
+		conn, err := net.Dial("udp", handler.address)
+		if err != nil {
+			return err
+		}
+
+		handler.conn = conn
+	}
+
+	if len(payload) <= handler.chunkSize {
+		_, err := handler.conn.Write(payload)
+		return err
+	}
+
+	return handler.writeChunks(payload)
+}
+
+// writeChunks sends payload as GELF chunks. The caller must hold the mutex.
+//
+//	chunk = magic:0x1e0f id:8 bytes sequence:byte count:byte data
+func (handler *GelfHandler) writeChunks(payload []byte) error {
+	const headerSize = 12
+
+	dataSize := handler.chunkSize - headerSize
+	if dataSize <= 0 {
+		return fmt.Errorf("GELF chunk size %d is too small", handler.chunkSize)
+	}
+
+	count := (len(payload) + dataSize - 1) / dataSize
+	if count > gelfMaxChunks {
+		return fmt.Errorf("%w: %d bytes need %d chunks", ErrGelfMessageTooLarge, len(payload), count)
+	}
+
+	id := binary.BigEndian.AppendUint64(nil, rand.Uint64())
+
+	chunk := make([]byte, 0, handler.chunkSize)
+	for i := 0; i < count; i++ {
+		data := payload[i*dataSize : min((i+1)*dataSize, len(payload))]
+
+		chunk = append(chunk[:0], gelfChunkMagic...)
+		chunk = append(chunk, id...)
+		chunk = append(chunk, byte(i), byte(count))
+		chunk = append(chunk, data...)
+
+		if _, err := handler.conn.Write(chunk); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (handler *GelfHandler) encode(logger *Logger, record Record) ([]byte, error) {
+	attrs := resolveAttrs(record.Attributes)
+
+	message := JsonHandlerAttributes{
+		{Key: "version", Value: "1.1"},
+		{Key: "host", Value: handler.hostname},
+		{Key: "short_message", Value: record.Message},
+		{Key: "timestamp", Value: float64(record.Time.UnixMicro()) / 1e6},
+		{Key: "level", Value: syslogSeverity(record.Level)},
+	}
+
+	if err, ok := firstError(attrs); ok {
+		if stack, ok := errorStack(err); ok {
+			message = append(message, JsonHandlerAttribute{Key: "full_message", Value: err.Error() + "\n" + stack})
+		}
+	}
+
+	message = message.set("_logger_id", logger.id.String())
+	if record.Caller != nil {
+		message = message.set("_file", record.Caller.File)
+		message = message.set("_line", record.Caller.Line)
+		message = message.set("_function", record.Caller.Function)
+	}
+
+	if record.TraceId != "" {
+		message = message.set("_trace_id", record.TraceId)
+	}
+
+	if record.SpanId != "" {
+		message = message.set("_span_id", record.SpanId)
+	}
+
+	message = gelfFields(message, "", attrs)
+
+	data, err := json.Marshal(message)
+	if err != nil {
+		return nil, err
+	}
+
+	if !handler.compress {
+		return data, nil
+	}
+
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// gelfFields adds attrs as additional fields. GELF only allows strings and numbers, other
+// values are sent using their default formatting.
+func gelfFields(message JsonHandlerAttributes, prefix string, attrs []Attribute) JsonHandlerAttributes {
+	for _, attr := range attrs {
+		name := prefix + gelfInvalidFieldChars.ReplaceAllString(attr.Key, "_")
+
+		switch v := attr.Value.(type) {
+		case []Attribute:
+			message = gelfFields(message, name+".", v)
+			continue
+		case string, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		case error:
+			attr.Value = v.Error()
+		default:
+			attr.Value = fmt.Sprintf("%v", v)
+		}
+
+		// The _id field is reserved by Graylog
+		if name == "id" {
+			name = "id_"
+		}
+
+		message = message.set("_"+name, attr.Value)
+	}
+
+	return message
+}
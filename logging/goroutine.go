@@ -0,0 +1,35 @@
+package logging
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+)
+
+// goroutineId returns the ID of the calling goroutine, or 0 if it cannot be determined. Go
+// does not expose the ID, it is parsed from the goroutine's stack trace.
+func goroutineId() uint64 {
+	var buf [64]byte
+	return parseGoroutineId(buf[:runtime.Stack(buf[:], false)])
+}
+
+// parseGoroutineId parses the ID from the first line of a stack trace, which has the form
+// "goroutine 123 [running]:", or returns 0 if stack does not start with such a line
+func parseGoroutineId(stack []byte) uint64 {
+	stack, ok := bytes.CutPrefix(stack, []byte("goroutine "))
+	if !ok {
+		return 0
+	}
+
+	end := bytes.IndexByte(stack, ' ')
+	if end < 0 {
+		return 0
+	}
+
+	id, err := strconv.ParseUint(string(stack[:end]), 10, 64)
+	if err != nil {
+		return 0
+	}
+
+	return id
+}
@@ -0,0 +1,51 @@
+package logging
+
+import "testing"
+
+func TestParseGoroutineId(t *testing.T) {
+	tests := map[string]uint64{
+		"goroutine 1 [running]:\nmain.main()":    1,
+		"goroutine 18446744073709551615 [idle]:": 18446744073709551615,
+		"goroutine 123":                          0,
+		"goroutine x [running]:":                 0,
+		"goroutine -1 [running]:":                0,
+		"routine 1 [running]:":                   0,
+		"":                                       0,
+	}
+
+	for stack, want := range tests {
+		if got := parseGoroutineId([]byte(stack)); got != want {
+			t.Errorf("parseGoroutineId(%q) = %d, want %d", stack, got, want)
+		}
+	}
+}
+
+func TestGoroutineId(t *testing.T) {
+	id := goroutineId()
+	if id == 0 {
+		t.Fatal("goroutineId returned 0")
+	}
+
+	other := make(chan uint64)
+	go func() { other <- goroutineId() }()
+
+	if otherId := <-other; otherId == 0 || otherId == id {
+		t.Errorf("got ID %d in another goroutine, want a different ID than %d", otherId, id)
+	}
+}
+
+func TestEmitGoroutineID(t *testing.T) {
+	var got any
+	logger := NewLogger()
+	logger.AddHandler(NewFuncHandler(LevelTrace, func(logger *Logger, record Record) error {
+		got, _ = record.AttrValue("goroutine")
+		return nil
+	}))
+
+	logger.EmitGoroutineID(true)
+	logger.Info("message")
+
+	if want := goroutineId(); got != want {
+		t.Errorf("got goroutine attribute %v, want %d", got, want)
+	}
+}
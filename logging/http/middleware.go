@@ -0,0 +1,95 @@
+package http
+
+import (
+	"context"
+	nethttp "net/http"
+	"time"
+
+	"github.com/link00000000/go-telemetry/logging"
+)
+
+// responseWriter records the status code and the number of bytes written to the response
+type responseWriter struct {
+	nethttp.ResponseWriter
+
+	status int
+	bytes  int64
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = nethttp.StatusOK
+	}
+
+	n, err := w.ResponseWriter.Write(p)
+	w.bytes += int64(n)
+
+	return n, err
+}
+
+// Unwrap allows [nethttp.ResponseController] to reach the wrapped writer
+func (w *responseWriter) Unwrap() nethttp.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Implements [nethttp.Flusher]
+func (w *responseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(nethttp.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// contextKey is the key of the request logger in the context of a request
+type contextKey struct{}
+
+// FromContext returns the request logger attached to ctx by [Middleware], or nil if there is
+// none
+func FromContext(ctx context.Context) *logging.Logger {
+	logger, _ := ctx.Value(contextKey{}).(*logging.Logger)
+	return logger
+}
+
+// Middleware logs an access record for every request handled by next. Each request gets
+// its own child logger, which next can retrieve with [FromContext] and which is closed once
+// the request has been handled. Server errors are logged at [logging.LevelError], client
+// errors at [logging.LevelWarn] and everything else at [logging.LevelInfo].
+func Middleware(logger *logging.Logger, next nethttp.Handler) nethttp.Handler {
+	return nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		requestLogger := logger.NewChildLogger()
+		defer requestLogger.Close()
+
+		start := time.Now()
+		writer := &responseWriter{ResponseWriter: w}
+
+		next.ServeHTTP(writer, r.WithContext(context.WithValue(r.Context(), contextKey{}, requestLogger)))
+
+		status := writer.status
+		if status == 0 {
+			status = nethttp.StatusOK
+		}
+
+		level := logging.LevelInfo
+		switch {
+		case status >= 500:
+			level = logging.LevelError
+		case status >= 400:
+			level = logging.LevelWarn
+		}
+
+		requestLogger.Log(level, r.Method+" "+r.URL.Path,
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", status,
+			"duration", time.Since(start),
+			"bytes", writer.bytes,
+		)
+	})
+}
@@ -0,0 +1,84 @@
+package http
+
+import (
+	nethttp "net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/link00000000/go-telemetry/logging"
+)
+
+func TestMiddleware(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		level  logging.Level
+	}{
+		{"implicit ok", 0, "hello", logging.LevelInfo},
+		{"redirect", nethttp.StatusFound, "", logging.LevelInfo},
+		{"client error", nethttp.StatusNotFound, "missing", logging.LevelWarn},
+		{"server error", nethttp.StatusInternalServerError, "failed!", logging.LevelError},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var records []logging.Record
+			logger := logging.NewLogger()
+			logger.AddHandler(logging.NewFuncHandler(logging.LevelTrace, func(logger *logging.Logger, record logging.Record) error {
+				records = append(records, record)
+				return nil
+			}))
+
+			var requestLogger *logging.Logger
+			next := nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+				requestLogger = FromContext(r.Context())
+				requestLogger.Debug("handling")
+
+				if test.status != 0 {
+					w.WriteHeader(test.status)
+				}
+
+				w.Write([]byte(test.body))
+			})
+
+			Middleware(logger, next).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/path", nil))
+
+			if requestLogger == nil {
+				t.Fatal("no request logger in the context")
+			}
+
+			if !requestLogger.IsClosed() {
+				t.Error("request logger was not closed after the request")
+			}
+
+			if len(records) != 2 || records[0].Message != "handling" {
+				t.Fatalf("got records %+v, want the record of the handler and the access record", records)
+			}
+
+			access := records[1]
+			if access.Level != test.level {
+				t.Errorf("got level %v, want %v", access.Level, test.level)
+			}
+
+			wantStatus := test.status
+			if wantStatus == 0 {
+				wantStatus = nethttp.StatusOK
+			}
+
+			if status, _ := access.AttrValue("status"); status != wantStatus {
+				t.Errorf("got status %v, want %d", status, wantStatus)
+			}
+
+			if bytes, _ := access.AttrValue("bytes"); bytes != int64(len(test.body)) {
+				t.Errorf("got bytes %v, want %d", bytes, len(test.body))
+			}
+		})
+	}
+}
+
+func TestFromContextWithoutMiddleware(t *testing.T) {
+	if logger := FromContext(httptest.NewRequest("GET", "/", nil).Context()); logger != nil {
+		t.Errorf("got logger %v, want nil", logger)
+	}
+}
@@ -0,0 +1,196 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const journalSocketPath = "/run/systemd/journal/socket"
+
+// Fields written by the handler itself, which attributes must not override
+var journalReservedFields = map[string]struct{}{
+	"PRIORITY":  {},
+	"MESSAGE":   {},
+	"CODE_FILE": {},
+	"CODE_LINE": {},
+	"CODE_FUNC": {},
+	"LOGGER_ID": {},
+}
+
+// syslogSeverity maps level to the matching syslog severity (RFC 5424 section 6.2.1)
+func syslogSeverity(level Level) int {
+	switch {
+	case level <= LevelDebug:
+		return 7
+	case level == LevelInfo:
+		return 6
+	case level == LevelWarn:
+		return 4
+	case level == LevelError:
+		return 3
+	case level == LevelFatal:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// JournalHandler writes records to the systemd journal using its native protocol, preserving
+// the priority, caller and attributes as journal fields
+type JournalHandler struct {
+	mutex sync.Mutex
+
+	level    Level
+	conn     net.Conn
+	fallback Handler
+}
+
+// NewJournalHandler connects to the local journal. If the journal socket is unavailable, all
+// calls are forwarded to fallback instead, or dropped if fallback is nil.
+func NewJournalHandler(level Level, fallback Handler) *JournalHandler {
+	handler := &JournalHandler{level: level, fallback: fallback}
+
+	if conn, err := net.Dial("unixgram", journalSocketPath); err == nil {
+		handler.conn = conn
+	}
+
+	return handler
+}
+
+// Available reports whether the handler is connected to the journal
+func (handler *JournalHandler) Available() bool {
+	return handler.conn != nil
+}
+
+// Implements [io.Closer]
+func (handler *JournalHandler) Close() error {
+	if handler.conn == nil {
+		return nil
+	}
+
+	return handler.conn.Close()
+}
+
+// Implements [logging.LeveledHandler]
+func (handler *JournalHandler) Level() Level {
+	return handler.level
+}
+
+// Implements [logging.Handler]
+func (handler *JournalHandler) OnLoggerCreated(logger *Logger, timestamp time.Time, caller *runtime.Frame) {
+	if handler.conn == nil && handler.fallback != nil {
+		handler.fallback.OnLoggerCreated(logger, timestamp, caller)
+	}
+}
+
+// Implements [logging.Handler]
+func (handler *JournalHandler) OnLoggerClosed(logger *Logger, timestamp time.Time, caller *runtime.Frame) error {
+	if handler.conn == nil && handler.fallback != nil {
+		return handler.fallback.OnLoggerClosed(logger, timestamp, caller)
+	}
+
+	return nil
+}
+
+// Implements [logging.Handler]
+func (handler *JournalHandler) HandleRecord(logger *Logger, record Record) error {
+	if handler.conn == nil {
+		if handler.fallback != nil {
+			return handler.fallback.HandleRecord(logger, record)
+		}
+
+		return nil
+	}
+
+	if record.Level < handler.level {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	writeJournalField(&buf, "PRIORITY", strconv.Itoa(syslogSeverity(record.Level)))
+	writeJournalField(&buf, "MESSAGE", record.Message)
+
+	if record.Caller != nil {
+		writeJournalField(&buf, "CODE_FILE", record.Caller.File)
+		writeJournalField(&buf, "CODE_LINE", strconv.Itoa(record.Caller.Line))
+		writeJournalField(&buf, "CODE_FUNC", record.Caller.Function)
+	}
+
+	writeJournalField(&buf, "LOGGER_ID", logger.id.String())
+	writeJournalAttrs(&buf, "", resolveAttrs(record.Attributes))
+
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	_, err := handler.conn.Write(buf.Bytes())
+	return err
+}
+
+func writeJournalAttrs(buf *bytes.Buffer, prefix string, attrs []Attribute) {
+	for _, attr := range attrs {
+		key := prefix + journalFieldName(attr.Key)
+
+		if group, ok := attr.Value.([]Attribute); ok {
+			writeJournalAttrs(buf, key+"_", group)
+			continue
+		}
+
+		// Attributes named like the fields of the record are kept under a prefixed name
+		if _, ok := journalReservedFields[key]; ok {
+			key = "ATTR_" + key
+		}
+
+		switch v := attr.Value.(type) {
+		case error:
+			writeJournalField(buf, key, v.Error())
+		default:
+			writeJournalField(buf, key, fmt.Sprintf("%v", v))
+		}
+	}
+}
+
+// writeJournalField encodes a single field, using the binary length-prefixed form for values
+// containing newlines
+func writeJournalField(buf *bytes.Buffer, name string, value string) {
+	if !strings.Contains(value, "\n") {
+		buf.WriteString(name)
+		buf.WriteByte('=')
+		buf.WriteString(value)
+		buf.WriteByte('\n')
+		return
+	}
+
+	buf.WriteString(name)
+	buf.WriteByte('\n')
+	binary.Write(buf, binary.LittleEndian, uint64(len(value)))
+	buf.WriteString(value)
+	buf.WriteByte('\n')
+}
+
+// journalFieldName converts key to a valid journal field name. Field names may only contain
+// uppercase letters, digits and underscores and must not start with an underscore or digit.
+func journalFieldName(key string) string {
+	var name strings.Builder
+
+	for _, r := range strings.ToUpper(key) {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			name.WriteRune(r)
+		} else {
+			name.WriteRune('_')
+		}
+	}
+
+	str := strings.TrimLeft(name.String(), "_0123456789")
+	if str == "" {
+		return "ATTR"
+	}
+
+	return str
+}
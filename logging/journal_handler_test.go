@@ -0,0 +1,33 @@
+package logging
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestWriteJournalAttrs(t *testing.T) {
+	attrs := []Attribute{
+		{Key: "message", Value: "attr"},
+		{Key: "priority", Value: 1},
+		{Key: "code", Value: []Attribute{{Key: "file", Value: "f"}, {Key: "other", Value: "o"}}},
+		{Key: "err", Value: errors.New("failed")},
+		{Key: "user.name", Value: "alice"},
+		{Key: "_private", Value: true},
+	}
+
+	var buf bytes.Buffer
+	writeJournalAttrs(&buf, "", attrs)
+
+	want := "ATTR_MESSAGE=attr\n" +
+		"ATTR_PRIORITY=1\n" +
+		"ATTR_CODE_FILE=f\n" +
+		"CODE_OTHER=o\n" +
+		"ERR=failed\n" +
+		"USER_NAME=alice\n" +
+		"PRIVATE=true\n"
+
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
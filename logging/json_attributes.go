@@ -0,0 +1,178 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+type JsonHandlerAttribute struct {
+	Key   string
+	Value any
+}
+
+// JsonHandlerAttributes is marshaled as a JSON object with the keys in the order the
+// attributes were logged. Groups are nested as JsonHandlerAttributes. When a key appears
+// more than once, the last value is kept at the position of the first.
+type JsonHandlerAttributes []JsonHandlerAttribute
+
+// set replaces the value of key, or appends it if it is not present
+func (attrs JsonHandlerAttributes) set(key string, value any) JsonHandlerAttributes {
+	for i := range attrs {
+		if attrs[i].Key == key {
+			attrs[i].Value = value
+			return attrs
+		}
+	}
+
+	return append(attrs, JsonHandlerAttribute{Key: key, Value: value})
+}
+
+// Get returns the value of key
+func (attrs JsonHandlerAttributes) Get(key string) (any, bool) {
+	for _, attr := range attrs {
+		if attr.Key == key {
+			return attr.Value, true
+		}
+	}
+
+	return nil, false
+}
+
+// Implements [json.Marshaler]
+func (attrs JsonHandlerAttributes) MarshalJSON() ([]byte, error) {
+	return attrs.appendJson(make([]byte, 0, 64*len(attrs)+2))
+}
+
+// appendJson appends the object to buf. Nested attributes are appended to the same buffer
+// and the most common values are formatted directly instead of marshaling each of them into
+// a slice of its own.
+func (attrs JsonHandlerAttributes) appendJson(buf []byte) ([]byte, error) {
+	buf = append(buf, '{')
+
+	for i, attr := range attrs {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+
+		buf = appendJsonString(buf, attr.Key)
+		buf = append(buf, ':')
+
+		var err error
+		if buf, err = appendJsonValue(buf, attr.Value); err != nil {
+			return nil, fmt.Errorf("attribute %q: %w", attr.Key, err)
+		}
+	}
+
+	return append(buf, '}'), nil
+}
+
+// appendJsonValue appends value encoded like [json.Marshal] would encode it
+func appendJsonValue(buf []byte, value any) ([]byte, error) {
+	switch v := value.(type) {
+	case nil:
+		return append(buf, "null"...), nil
+	case string:
+		return appendJsonString(buf, v), nil
+	case bool:
+		return strconv.AppendBool(buf, v), nil
+	case int:
+		return strconv.AppendInt(buf, int64(v), 10), nil
+	case int64:
+		return strconv.AppendInt(buf, v, 10), nil
+	case uint64:
+		return strconv.AppendUint(buf, v, 10), nil
+	case time.Duration:
+		return strconv.AppendInt(buf, int64(v), 10), nil
+	case JsonHandlerAttributes:
+		return v.appendJson(buf)
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(buf, data...), nil
+}
+
+// appendJsonString appends s as a quoted JSON string
+func appendJsonString(buf []byte, s string) []byte {
+	if !jsonPlainString(s) {
+		// Strings that need escaping are rare, leave the details of escaping to encoding/json
+		data, _ := json.Marshal(s)
+		return append(buf, data...)
+	}
+
+	buf = append(buf, '"')
+	buf = append(buf, s...)
+	return append(buf, '"')
+}
+
+// jsonPlainString reports whether s can be written between quotes as is, which is the case
+// for printable ASCII without the characters that are escaped by [json.Marshal]
+func jsonPlainString(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c < 0x20 || c > 0x7e || c == '"' || c == '\\' || c == '<' || c == '>' || c == '&' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Implements [json.Unmarshaler]
+func (attrs *JsonHandlerAttributes) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*attrs = nil
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+
+	if token != json.Delim('{') {
+		return fmt.Errorf("attributes: expected object, got %v", token)
+	}
+
+	parsed := make(JsonHandlerAttributes, 0)
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+
+		key, ok := token.(string)
+		if !ok {
+			return fmt.Errorf("attributes: expected key, got %v", token)
+		}
+
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			return err
+		}
+
+		var value any
+		if trimmed := bytes.TrimSpace(raw); len(trimmed) > 0 && trimmed[0] == '{' {
+			var group JsonHandlerAttributes
+			if err := group.UnmarshalJSON(trimmed); err != nil {
+				return err
+			}
+
+			value = group
+		} else if err := json.Unmarshal(raw, &value); err != nil {
+			return err
+		}
+
+		parsed = parsed.set(key, value)
+	}
+
+	*attrs = parsed
+	return nil
+}
@@ -0,0 +1,133 @@
+package logging
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"unsafe"
+)
+
+// JsonHandlerFieldNames maps the fields in the data of JSON messages to the keys they are
+// written as. Empty names keep the default key. The version, type and data keys of the
+// envelope are not renamed.
+type JsonHandlerFieldNames struct {
+	Time       string
+	Level      string
+	Kind       string
+	Message    string
+	Error      string
+	ErrorChain string
+	ErrorStack string
+	Caller     string
+	Logger     string
+	Attributes string
+	TraceId    string
+	SpanId     string
+	Stats      string
+	Host       string
+	Process    string
+}
+
+// renames returns the default keys mapped to the configured names, or nil if no field is
+// renamed
+func (names JsonHandlerFieldNames) renames() map[string]string {
+	fields := []struct {
+		key  string
+		name string
+	}{
+		{"time", names.Time},
+		{"level", names.Level},
+		{"kind", names.Kind},
+		{"message", names.Message},
+		{"error", names.Error},
+		{"errorChain", names.ErrorChain},
+		{"errorStack", names.ErrorStack},
+		{"caller", names.Caller},
+		{"logger", names.Logger},
+		{"attributes", names.Attributes},
+		{"traceId", names.TraceId},
+		{"spanId", names.SpanId},
+		{"stats", names.Stats},
+		{"host", names.Host},
+		{"process", names.Process},
+	}
+
+	var renames map[string]string
+	for _, field := range fields {
+		if field.name == "" || field.name == field.key {
+			continue
+		}
+
+		if renames == nil {
+			renames = make(map[string]string)
+		}
+
+		renames[field.key] = field.name
+	}
+
+	return renames
+}
+
+// renamedJsonTypes caches the types created by [renamedJsonType]
+var renamedJsonTypes sync.Map
+
+type renamedJsonTypeKey struct {
+	data  reflect.Type
+	names JsonHandlerFieldNames
+}
+
+// renamedJsonType returns a struct type with the same fields as data whose JSON keys are
+// renamed, which data can be converted to since the types only differ in their tags
+func renamedJsonType(data reflect.Type, names JsonHandlerFieldNames) reflect.Type {
+	key := renamedJsonTypeKey{data: data, names: names}
+	if t, ok := renamedJsonTypes.Load(key); ok {
+		return t.(reflect.Type)
+	}
+
+	renames := names.renames()
+
+	fields := make([]reflect.StructField, data.NumField())
+	for i := range fields {
+		field := data.Field(i)
+
+		tag := field.Tag.Get("json")
+		name, options, _ := strings.Cut(tag, ",")
+		if renamed, ok := renames[name]; ok {
+			if options != "" {
+				renamed += "," + options
+			}
+
+			field.Tag = reflect.StructTag(fmt.Sprintf("json:%q", renamed))
+		}
+
+		fields[i] = field
+	}
+
+	t, _ := renamedJsonTypes.LoadOrStore(key, reflect.StructOf(fields))
+	return t.(reflect.Type)
+}
+
+// renameJsonFields returns message with the keys of its data renamed. The data is converted
+// to a struct type with renamed tags, so that it is encoded like the original, in the same
+// order and omitting the same fields.
+func renameJsonFields(message any, names JsonHandlerFieldNames) any {
+	switch m := message.(type) {
+	case JsonHandlerMessage[JsonHandlerLoggerCreated]:
+		return renameJsonMessage(m, names)
+	case JsonHandlerMessage[JsonHandlerLoggerClosed]:
+		return renameJsonMessage(m, names)
+	case JsonHandlerMessage[JsonHandlerRecord]:
+		return renameJsonMessage(m, names)
+	default:
+		return message
+	}
+}
+
+func renameJsonMessage[T any](message JsonHandlerMessage[T], names JsonHandlerFieldNames) JsonHandlerMessage[any] {
+	// The renamed type has the same fields as T and therefore the same layout, so the data can
+	// be reinterpreted in place instead of being copied by a checked conversion
+	renamed := reflect.NewAt(renamedJsonType(reflect.TypeFor[T](), names), unsafe.Pointer(&message.Data))
+
+	return JsonHandlerMessage[any]{Version: message.Version, Type: message.Type, Data: renamed.Interface()}
+}
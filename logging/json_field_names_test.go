@@ -0,0 +1,73 @@
+package logging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJsonHandlerFieldNames(t *testing.T) {
+	names := JsonHandlerFieldNames{
+		Time:       "@timestamp",
+		Level:      "severity",
+		Message:    "msg",
+		Error:      "err",
+		ErrorChain: "causes",
+		Caller:     "source",
+		Attributes: "fields",
+		TraceId:    "trace",
+		Stats:      "counts",
+	}
+
+	// The renamed output must match the default output with only the keys replaced
+	replacer := strings.NewReplacer(
+		`"time":`, `"@timestamp":`,
+		`"level":`, `"severity":`,
+		`"message":`, `"msg":`,
+		`"error":`, `"err":`,
+		`"errorChain":`, `"causes":`,
+		`"caller":`, `"source":`,
+		`"attributes":`, `"fields":`,
+		`"traceId":`, `"trace":`,
+		`"stats":`, `"counts":`,
+	)
+
+	logger := NewLogger()
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	record := Record{
+		Time:       now,
+		Level:      LevelError,
+		Message:    "failed",
+		Caller:     &runtime.Frame{File: "main.go", Line: 12},
+		TraceId:    "abc",
+		Attributes: []Attribute{{Key: "cause", Value: fmt.Errorf("request: %w", errors.New("timeout"))}, {Key: "id", Value: 1}},
+	}
+
+	messages := map[string]func(handler JsonHandler) error{
+		"record": func(handler JsonHandler) error { return handler.HandleRecord(logger, record) },
+		"closed": func(handler JsonHandler) error { return handler.OnLoggerClosed(logger, now, record.Caller) },
+		"created": func(handler JsonHandler) error {
+			handler.OnLoggerCreated(logger, now, record.Caller)
+			return nil
+		},
+	}
+
+	for name, write := range messages {
+		var defaultBuf, renamedBuf bytes.Buffer
+		if err := write(NewJsonHandler(&defaultBuf, LevelTrace)); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := write(NewJsonHandler(&renamedBuf, LevelTrace).WithFieldNames(names)); err != nil {
+			t.Fatal(err)
+		}
+
+		if got, want := renamedBuf.String(), replacer.Replace(defaultBuf.String()); got != want {
+			t.Errorf("%s: got %s, want %s", name, got, want)
+		}
+	}
+}
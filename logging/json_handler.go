@@ -1,12 +1,27 @@
 package logging
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"io"
 	"runtime"
+	"slices"
+	"sync"
 	"time"
 )
 
+// JsonSchemaVersion is written in the version field of every JSON message and is
+// incremented whenever the shape of the messages changes.
+//
+//	1: initial versioned schema
+//	2: stats on logger closed messages
+//	3: attributes keep the order they were logged in
+//	4: optional pc and function of the caller
+//	5: kind of records
+//	6: optional host and process of records
+const JsonSchemaVersion = 6
+
 type JsonHandlerMessageType int
 
 const (
@@ -18,6 +33,20 @@ const (
 type JsonHandlerCaller struct {
 	File string `json:"file"`
 	Line int    `json:"line"`
+
+	// Only written with [JsonHandler.WithCallerDetails]
+	PC       uint64 `json:"pc,omitempty"`
+	Function string `json:"function,omitempty"`
+}
+
+// JsonHandlerHost is written with [Logger.EmitHostname]
+type JsonHandlerHost struct {
+	Name string `json:"name"`
+}
+
+// JsonHandlerProcess is written with [Logger.EmitPID]
+type JsonHandlerProcess struct {
+	Pid int `json:"pid"`
 }
 
 type JsonHandlerLogger struct {
@@ -37,51 +66,218 @@ type JsonHandlerLoggerClosed struct {
 	Time   time.Time         `json:"time"`
 	Caller JsonHandlerCaller `json:"caller"`
 	Logger JsonHandlerLogger `json:"logger"`
+
+	// Record counts keyed by level name, and the lifetime of the logger in durationMs
+	Stats map[string]int64 `json:"stats"`
 }
 
 type JsonHandlerRecord struct {
-	Time    time.Time         `json:"time"`
-	Level   string            `json:"level"`
-	Message string            `json:"message"`
-	Error   *string           `json:"error"`
-	Caller  JsonHandlerCaller `json:"caller"`
-	Logger  JsonHandlerLogger `json:"logger"`
+	Time       time.Time             `json:"time"`
+	Level      string                `json:"level"`
+	Kind       string                `json:"kind"`
+	Message    string                `json:"message"`
+	Error      *string               `json:"error"`
+	ErrorChain []string              `json:"errorChain,omitempty"`
+	ErrorStack *string               `json:"errorStack,omitempty"`
+	Caller     JsonHandlerCaller     `json:"caller"`
+	Logger     JsonHandlerLogger     `json:"logger"`
+	Attributes JsonHandlerAttributes `json:"attributes"`
+	TraceId    string                `json:"traceId,omitempty"`
+	SpanId     string                `json:"spanId,omitempty"`
+	Host       *JsonHandlerHost      `json:"host,omitempty"`
+	Process    *JsonHandlerProcess   `json:"process,omitempty"`
 }
 
 type JsonHandlerMessage[T any] struct {
-	Type JsonHandlerMessageType `json:"type"`
-	Data T                      `json:"data"`
+	Version int                    `json:"version"`
+	Type    JsonHandlerMessageType `json:"type"`
+	Data    T                      `json:"data"`
 }
 
 func NewJsonLoggerCreatedMessage() JsonHandlerMessage[JsonHandlerLoggerCreated] {
-	return JsonHandlerMessage[JsonHandlerLoggerCreated]{Type: JsonHandlerMessageType_LoggerCreated, Data: JsonHandlerLoggerCreated{}}
+	return JsonHandlerMessage[JsonHandlerLoggerCreated]{Version: JsonSchemaVersion, Type: JsonHandlerMessageType_LoggerCreated, Data: JsonHandlerLoggerCreated{}}
 }
 
 func NewJsonLoggerClosedMessage() JsonHandlerMessage[JsonHandlerLoggerClosed] {
-	return JsonHandlerMessage[JsonHandlerLoggerClosed]{Type: JsonHandlerMessageType_LoggerClosed, Data: JsonHandlerLoggerClosed{}}
+	return JsonHandlerMessage[JsonHandlerLoggerClosed]{Version: JsonSchemaVersion, Type: JsonHandlerMessageType_LoggerClosed, Data: JsonHandlerLoggerClosed{}}
 }
 
 func NewJsonLoggerRecordMessage() JsonHandlerMessage[JsonHandlerRecord] {
-	return JsonHandlerMessage[JsonHandlerRecord]{Type: JsonHandlerMessageType_Record, Data: JsonHandlerRecord{}}
+	return JsonHandlerMessage[JsonHandlerRecord]{Version: JsonSchemaVersion, Type: JsonHandlerMessageType_Record, Data: JsonHandlerRecord{}}
+}
+
+type jsonEncoder struct {
+	buf     bytes.Buffer
+	encoder *json.Encoder
+}
+
+// Buffers that grew larger than this are not returned to the pool to avoid holding on to
+// the memory of an occasional large record
+const maxPooledJsonBufferSize = 64 * 1024
+
+var jsonEncoderPool = sync.Pool{
+	New: func() any {
+		e := &jsonEncoder{}
+		e.encoder = json.NewEncoder(&e.buf)
+		return e
+	},
+}
+
+// JsonHandlerFraming selects how consecutive messages are separated in the output
+type JsonHandlerFraming int
+
+const (
+	// Every message is followed by a newline
+	JsonHandlerFraming_NDJSON JsonHandlerFraming = iota
+	// Messages are elements of a single array, which is terminated by [JsonHandler.Close]
+	JsonHandlerFraming_Array
+	// Every message is followed by the delimiter set with [JsonHandler.SetDelimiter]
+	JsonHandlerFraming_Delimited
+)
+
+// jsonHandlerConfig holds the settings of a JsonHandler that would otherwise make it
+// incomparable, so that it can still be removed with [Logger.RemoveHandler]
+type jsonHandlerConfig struct {
+	valueEncoder    func(value any) (any, bool)
+	callerFormatter CallerFormatter
+
+	// Guards the array framing state, so that elements are separated in the order they are
+	// written
+	mutex     sync.Mutex
+	framing   JsonHandlerFraming
+	delimiter string
+	started   bool
+	closed    bool
 }
 
 type JsonHandler struct {
 	writer io.Writer
 	level  Level
+
+	config *jsonHandlerConfig
+
+	indentPrefix string
+	indent       string
+
+	callerDetails bool
+
+	fieldNames JsonHandlerFieldNames
 }
 
+// NewJsonHandler creates a handler writing each message as compact JSON on a single line
+// (NDJSON), see [JsonHandler.WithIndent] for human readable output
 func NewJsonHandler(writer io.Writer, level Level) JsonHandler {
-	return JsonHandler{writer: writer, level: level}
+	return JsonHandler{writer: writer, level: level, config: &jsonHandlerConfig{}}
+}
+
+// NewJsonHandlerMulti creates a handler that marshals each message once and writes the same
+// bytes to every writer. A failing writer does not prevent writing to the others.
+func NewJsonHandlerMulti(level Level, writers ...io.Writer) JsonHandler {
+	return NewJsonHandler(&teeWriter{writers: writers}, level)
+}
+
+// teeWriter writes the same bytes to every writer, collecting their errors
+type teeWriter struct {
+	writers []io.Writer
+}
+
+// Implements [io.Writer]
+func (tee *teeWriter) Write(p []byte) (int, error) {
+	errs := make([]error, 0)
+	for _, writer := range tee.writers {
+		if _, err := writer.Write(p); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return len(p), errors.Join(errs...)
+}
+
+// SetValueEncoder registers fn to transform each attribute value before it is marshaled,
+// for example to format durations or redact secrets. If fn returns false the value is
+// marshaled unchanged. The encoder is shared by all copies of the handler and should be set
+// before the handler is used.
+func (handler JsonHandler) SetValueEncoder(fn func(value any) (any, bool)) {
+	handler.config.valueEncoder = fn
+}
+
+// WithIndent returns a copy of the handler that indents each message over multiple lines like
+// [json.MarshalIndent]. Messages are still terminated by a newline, but the output is no
+// longer valid NDJSON.
+func (handler JsonHandler) WithIndent(prefix string, indent string) JsonHandler {
+	handler.indentPrefix = prefix
+	handler.indent = indent
+	return handler
+}
+
+// WithCallerDetails returns a copy of the handler that also writes the program counter and
+// function name of the caller, for symbolication when the line alone is ambiguous
+func (handler JsonHandler) WithCallerDetails(enabled bool) JsonHandler {
+	handler.callerDetails = enabled
+	return handler
+}
+
+// WithFieldNames returns a copy of the handler that writes the fields of created, closed and
+// record messages under the keys in names. Messages with renamed fields can not be read back
+// with [ParseJsonRecords].
+func (handler JsonHandler) WithFieldNames(names JsonHandlerFieldNames) JsonHandler {
+	handler.fieldNames = names
+	return handler
+}
+
+// SetFraming selects how messages are separated in the stream. Like the value encoder, it is
+// shared by all copies of the handler and should be set before the handler is used.
+func (handler JsonHandler) SetFraming(framing JsonHandlerFraming) {
+	handler.config.framing = framing
+}
+
+// SetDelimiter selects [JsonHandlerFraming_Delimited], writing delimiter after every message
+func (handler JsonHandler) SetDelimiter(delimiter string) {
+	handler.config.framing = JsonHandlerFraming_Delimited
+	handler.config.delimiter = delimiter
+}
+
+// Close terminates the array when using [JsonHandlerFraming_Array], messages handled
+// afterwards are dropped. It does nothing for the other framings and does not close the
+// writer.
+func (handler JsonHandler) Close() error {
+	if handler.config == nil || handler.config.framing != JsonHandlerFraming_Array {
+		return nil
+	}
+
+	handler.config.mutex.Lock()
+	defer handler.config.mutex.Unlock()
+
+	if handler.config.closed {
+		return nil
+	}
+
+	handler.config.closed = true
+
+	if !handler.config.started {
+		return writeRecord(handler.writer, []byte("[]\n"))
+	}
+
+	return writeRecord(handler.writer, []byte("\n]\n"))
+}
+
+// SetCallerFormatter replaces the absolute path written as the caller file with the result
+// of fn. Like the value encoder, it is shared by all copies of the handler.
+func (handler JsonHandler) SetCallerFormatter(fn CallerFormatter) {
+	handler.config.callerFormatter = fn
+}
+
+// Implements [logging.LeveledHandler]
+func (handler JsonHandler) Level() Level {
+	return handler.level
 }
 
 // Implements [logging.Handler]
 func (handler JsonHandler) OnLoggerCreated(logger *Logger, timestamp time.Time, caller *runtime.Frame) {
 	loggerCreated := NewJsonLoggerCreatedMessage()
-	loggerCreated.Data.Time = timestamp
+	loggerCreated.Data.Time = timestamp.UTC()
 
-	loggerCreated.Data.Caller = JsonHandlerCaller{}
-	loggerCreated.Data.Caller.File = caller.File
-	loggerCreated.Data.Caller.Line = caller.Line
+	loggerCreated.Data.Caller = handler.jsonCaller(caller)
 
 	loggerCreated.Data.Logger.Id = logger.id.String()
 	loggerCreated.Data.Logger.Root = logger.RootLogger().id.String()
@@ -91,28 +287,23 @@ func (handler JsonHandler) OnLoggerCreated(logger *Logger, timestamp time.Time,
 		loggerCreated.Data.Logger.Parent = &str
 	}
 
-	loggerCreated.Data.Logger.Children = make([]string, len(logger.children))
-	for i, c := range logger.children {
+	children := logger.childLoggers()
+	loggerCreated.Data.Logger.Children = make([]string, len(children))
+	for i, c := range children {
 		loggerCreated.Data.Logger.Children[i] = c.id.String()
 	}
 
-	data, err := json.Marshal(loggerCreated)
-	if err != nil {
-		panic(err)
-	}
-
-	// TODO: Handle error?
-	handler.writer.Write(append(data, byte('\n')))
+	// OnLoggerCreated has no way to report errors, a failed write will surface on the next
+	// record or when the logger is closed
+	handler.writeMessages(loggerCreated)
 }
 
 // Implements [logging.Handler]
 func (handler JsonHandler) OnLoggerClosed(logger *Logger, timestamp time.Time, caller *runtime.Frame) error {
 	loggerClosed := NewJsonLoggerClosedMessage()
-	loggerClosed.Data.Time = timestamp
+	loggerClosed.Data.Time = timestamp.UTC()
 
-	loggerClosed.Data.Caller = JsonHandlerCaller{}
-	loggerClosed.Data.Caller.File = caller.File
-	loggerClosed.Data.Caller.Line = caller.Line
+	loggerClosed.Data.Caller = handler.jsonCaller(caller)
 
 	loggerClosed.Data.Logger.Id = logger.id.String()
 	loggerClosed.Data.Logger.Root = logger.RootLogger().id.String()
@@ -122,22 +313,20 @@ func (handler JsonHandler) OnLoggerClosed(logger *Logger, timestamp time.Time, c
 		loggerClosed.Data.Logger.Parent = &str
 	}
 
-	loggerClosed.Data.Logger.Children = make([]string, len(logger.children))
-	for i, c := range logger.children {
+	children := logger.childLoggers()
+	loggerClosed.Data.Logger.Children = make([]string, len(children))
+	for i, c := range children {
 		loggerClosed.Data.Logger.Children[i] = c.id.String()
 	}
 
-	data, err := json.Marshal(loggerClosed)
-	if err != nil {
-		return err
-	}
-
-	handler.writer.Write(append(data, byte('\n')))
-	if err != nil {
-		return err
+	stats := logger.Stats()
+	loggerClosed.Data.Stats = make(map[string]int64, len(stats.Counts)+1)
+	for level, count := range stats.Counts {
+		loggerClosed.Data.Stats[level.String()] = int64(count)
 	}
+	loggerClosed.Data.Stats["durationMs"] = timestamp.Sub(stats.CreatedAt).Milliseconds()
 
-	return nil
+	return handler.writeMessages(loggerClosed)
 }
 
 // Implements [logging.Handler]
@@ -146,29 +335,69 @@ func (handler JsonHandler) HandleRecord(logger *Logger, record Record) error {
 		return nil
 	}
 
-	message := NewJsonLoggerRecordMessage()
-	message.Data.Time = record.Time
-
-	switch record.Level {
-	case LevelDebug:
-		message.Data.Level = "debug"
-	case LevelInfo:
-		message.Data.Level = "info"
-	case LevelWarn:
-		message.Data.Level = "warn"
-	case LevelError:
-		message.Data.Level = "error"
-	case LevelFatal:
-		message.Data.Level = "fatal"
-	case LevelPanic:
-		message.Data.Level = "panic"
+	return handler.writeMessages(handler.recordMessage(logger, record))
+}
+
+// Implements [logging.BatchHandler]
+func (handler JsonHandler) HandleRecords(logger *Logger, records []Record) error {
+	messages := make([]any, 0, len(records))
+	for _, record := range records {
+		if record.Level >= handler.level {
+			messages = append(messages, handler.recordMessage(logger, record))
+		}
 	}
 
+	return handler.writeMessages(messages...)
+}
+
+func (handler JsonHandler) recordMessage(logger *Logger, record Record) JsonHandlerMessage[JsonHandlerRecord] {
+	message := NewJsonLoggerRecordMessage()
+	message.Data.Time = record.Time.UTC()
+
+	message.Data.Level = record.Level.String()
+	message.Data.Kind = record.Kind.String()
 	message.Data.Message = record.Message
+	message.Data.TraceId = record.TraceId
+	message.Data.SpanId = record.SpanId
+
+	attrs := resolveAttrs(record.Attributes)
+
+	emitHostname, emitPid := logger.emitsProcess()
+	if emitHostname {
+		if value, ok := takeLastAttr(&attrs, "hostname"); ok {
+			name, _ := value.(string)
+			message.Data.Host = &JsonHandlerHost{Name: name}
+		}
+	}
+
+	if emitPid {
+		if value, ok := takeLastAttr(&attrs, "pid"); ok {
+			pid, _ := value.(int)
+			message.Data.Process = &JsonHandlerProcess{Pid: pid}
+		}
+	}
+
+	var valueEncoder func(any) (any, bool)
+	if handler.config != nil {
+		valueEncoder = handler.config.valueEncoder
+	}
+
+	message.Data.Attributes = jsonAttributes(attrs, valueEncoder)
+
+	if err, ok := firstError(attrs); ok {
+		errStr := err.Error()
+		message.Data.Error = &errStr
+
+		if chain := errorChain(err); len(chain) > 0 {
+			message.Data.ErrorChain = chain
+		}
+
+		if stack, ok := errorStack(err); ok {
+			message.Data.ErrorStack = &stack
+		}
+	}
 
-	message.Data.Caller = JsonHandlerCaller{}
-	message.Data.Caller.File = record.Caller.File
-	message.Data.Caller.Line = record.Caller.Line
+	message.Data.Caller = handler.jsonCaller(record.Caller)
 
 	message.Data.Logger.Id = logger.id.String()
 	message.Data.Logger.Root = logger.RootLogger().id.String()
@@ -178,20 +407,140 @@ func (handler JsonHandler) HandleRecord(logger *Logger, record Record) error {
 		message.Data.Logger.Parent = &str
 	}
 
-	message.Data.Logger.Children = make([]string, len(logger.children))
-	for i, c := range logger.children {
+	children := logger.childLoggers()
+	message.Data.Logger.Children = make([]string, len(children))
+	for i, c := range children {
 		message.Data.Logger.Children[i] = c.id.String()
 	}
 
-	data, err := json.Marshal(message)
-	if err != nil {
-		return err
+	return message
+}
+
+// takeLastAttr removes the last top-level attribute with key from attrs and returns its value.
+// Attributes added by the logger are appended after those of the call.
+func takeLastAttr(attrs *[]Attribute, key string) (any, bool) {
+	for i := len(*attrs) - 1; i >= 0; i-- {
+		if (*attrs)[i].Key == key {
+			value := (*attrs)[i].Value
+			*attrs = slices.Delete(*attrs, i, i+1)
+			return value, true
+		}
+	}
+
+	return nil, false
+}
+
+// jsonCaller returns the caller object for frame, which is left empty if frame is nil
+func (handler JsonHandler) jsonCaller(frame *runtime.Frame) JsonHandlerCaller {
+	if frame == nil {
+		return JsonHandlerCaller{}
+	}
+
+	file := frame.File
+	if handler.config != nil && handler.config.callerFormatter != nil {
+		file = handler.config.callerFormatter(frame)
+	}
+
+	caller := JsonHandlerCaller{File: file, Line: frame.Line}
+	if handler.callerDetails {
+		caller.PC = uint64(frame.PC)
+		caller.Function = frame.Function
+	}
+
+	return caller
+}
+
+// writeMessages encodes each message followed by a newline and writes them in a single call
+func (handler JsonHandler) writeMessages(messages ...any) error {
+	e := jsonEncoderPool.Get().(*jsonEncoder)
+	defer func() {
+		if e.buf.Cap() <= maxPooledJsonBufferSize {
+			e.buf.Reset()
+			jsonEncoderPool.Put(e)
+		}
+	}()
+
+	// Encoders are pooled across handlers, always set the indentation of this handler
+	e.encoder.SetIndent(handler.indentPrefix, handler.indent)
+
+	framing := JsonHandlerFraming_NDJSON
+	if handler.config != nil {
+		framing = handler.config.framing
+	}
+
+	if framing == JsonHandlerFraming_Array {
+		handler.config.mutex.Lock()
+		defer handler.config.mutex.Unlock()
+
+		if handler.config.closed {
+			return nil
+		}
 	}
 
-	handler.writer.Write(append(data, byte('\n')))
-	if err != nil {
-		return err
+	renamed := handler.fieldNames != JsonHandlerFieldNames{}
+
+	started := framing == JsonHandlerFraming_Array && handler.config.started
+	for _, message := range messages {
+		if renamed {
+			message = renameJsonFields(message, handler.fieldNames)
+		}
+
+		if framing == JsonHandlerFraming_Array {
+			if started {
+				e.buf.WriteString(",\n")
+			} else {
+				e.buf.WriteString("[\n")
+				started = true
+			}
+		}
+
+		if err := e.encoder.Encode(message); err != nil {
+			return err
+		}
+
+		if framing != JsonHandlerFraming_NDJSON {
+			// Replace the newline written by the encoder
+			e.buf.Truncate(e.buf.Len() - 1)
+		}
+
+		if framing == JsonHandlerFraming_Delimited {
+			e.buf.WriteString(handler.config.delimiter)
+		}
+	}
+
+	if framing == JsonHandlerFraming_Array {
+		handler.config.started = started
+	}
+
+	if e.buf.Len() == 0 {
+		return nil
+	}
+
+	return writeRecord(handler.writer, e.buf.Bytes())
+}
+
+// jsonAttributes converts attrs to ordered JSON attributes, nesting groups. If valueEncoder is
+// not nil it is applied to every value that is not a group.
+func jsonAttributes(attrs []Attribute, valueEncoder func(any) (any, bool)) JsonHandlerAttributes {
+	m := make(JsonHandlerAttributes, 0, len(attrs))
+
+	for _, attr := range attrs {
+		value := attr.Value
+		if _, isGroup := value.([]Attribute); !isGroup && valueEncoder != nil {
+			if encoded, ok := valueEncoder(value); ok {
+				value = encoded
+			}
+		}
+
+		switch v := value.(type) {
+		case []Attribute:
+			m = m.set(attr.Key, jsonAttributes(v, valueEncoder))
+		case error:
+			m = m.set(attr.Key, v.Error())
+		default:
+			m = m.set(attr.Key, v)
+		}
 	}
 
-	return nil
+	return m
 }
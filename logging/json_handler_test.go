@@ -0,0 +1,177 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// decodeJsonRecord decodes the attributes of the single record written to buf
+func decodeJsonRecord(t *testing.T, buf *bytes.Buffer) map[string]any {
+	t.Helper()
+
+	var message struct {
+		Type JsonHandlerMessageType `json:"type"`
+		Data struct {
+			Attributes map[string]any `json:"attributes"`
+		} `json:"data"`
+	}
+
+	if err := json.Unmarshal(buf.Bytes(), &message); err != nil {
+		t.Fatalf("invalid message %q: %v", buf.String(), err)
+	}
+
+	if message.Type != JsonHandlerMessageType_Record {
+		t.Fatalf("got message type %v, want %v", message.Type, JsonHandlerMessageType_Record)
+	}
+
+	return message.Data.Attributes
+}
+
+func TestJsonHandlerNestedGroups(t *testing.T) {
+	var buf bytes.Buffer
+	handler := NewJsonHandler(&buf, LevelTrace)
+
+	record := Record{
+		Message: "request",
+		Attributes: []Attribute{
+			Group("http",
+				"method", "GET",
+				Group("response",
+					"status", 200,
+					Group("headers", "content-type", "text/plain"),
+				),
+			),
+		},
+	}
+
+	if err := handler.HandleRecord(NewLogger(), record); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]any{
+		"http": map[string]any{
+			"method": "GET",
+			"response": map[string]any{
+				"status": 200.0,
+				"headers": map[string]any{
+					"content-type": "text/plain",
+				},
+			},
+		},
+	}
+
+	if got := decodeJsonRecord(t, &buf); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestJsonHandlerAttributeOrder(t *testing.T) {
+	var buf bytes.Buffer
+	handler := NewJsonHandler(&buf, LevelTrace)
+
+	// The keys are in neither alphabetical nor hash order, and the repeated key keeps the
+	// position of its first occurrence
+	record := Record{
+		Message: "message",
+		Attributes: []Attribute{
+			{Key: "zebra", Value: 1},
+			{Key: "apple", Value: 2},
+			{Key: "mango", Value: []Attribute{{Key: "z", Value: 1}, {Key: "a", Value: 2}}},
+			{Key: "banana", Value: 3},
+			{Key: "zebra", Value: 4},
+		},
+	}
+
+	if err := handler.HandleRecord(NewLogger(), record); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `"attributes":{"zebra":4,"apple":2,"mango":{"z":1,"a":2},"banana":3}`
+	if got := buf.String(); !strings.Contains(got, want) {
+		t.Errorf("got %s, want attributes %s", got, want)
+	}
+}
+
+func TestJsonHandlerAttributesMatchMarshal(t *testing.T) {
+	values := []any{
+		nil, "plain", "quote\" and \\", "<tag> & more", "tab\tnewline\n", "caf\u00e9", "\u2028", "bad \xff utf-8",
+		true, false, 0, -12, int64(1) << 62, uint64(1) << 63, 15 * time.Millisecond, 1.5, []int{1, 2},
+		errors.New("ignored by the fast paths"),
+	}
+
+	for _, value := range values {
+		want, err := json.Marshal(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := appendJsonValue(nil, value)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(got) != string(want) {
+			t.Errorf("value %#v: got %s, want %s", value, got, want)
+		}
+	}
+
+	attrs := JsonHandlerAttributes{{Key: "<key>", Value: "value"}, {Key: "group", Value: JsonHandlerAttributes{{Key: "a", Value: 1}}}}
+	got, err := json.Marshal(attrs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := `{"\u003ckey\u003e":"value","group":{"a":1}}`; string(got) != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func BenchmarkJsonHandler(b *testing.B) {
+	handler := NewJsonHandler(io.Discard, LevelTrace)
+	logger := NewLogger()
+
+	record := Record{
+		Time:    time.Now(),
+		Level:   LevelInfo,
+		Message: "request done",
+		Caller:  &runtime.Frame{File: "/src/main.go", Line: 12, Function: "main.main"},
+		Attributes: []Attribute{
+			String("method", "GET"),
+			Int("status", 200),
+			Duration("elapsed", 15*time.Millisecond),
+		},
+	}
+
+	b.Run("pooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			handler.HandleRecord(logger, record)
+		}
+	})
+
+	b.Run("renamed", func(b *testing.B) {
+		handler := handler.WithFieldNames(JsonHandlerFieldNames{Time: "@timestamp", Message: "msg"})
+
+		b.ReportAllocs()
+		for range b.N {
+			handler.HandleRecord(logger, record)
+		}
+	})
+
+	// Marshals each message into a new slice as before the encoders were pooled. The pool only
+	// saves the output buffer, the remaining allocations are the same for both.
+	b.Run("unpooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			data, _ := json.Marshal(handler.recordMessage(logger, record))
+			io.Discard.Write(append(data, '\n'))
+		}
+	})
+}
@@ -0,0 +1,160 @@
+package logging
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"runtime"
+)
+
+var ErrUnexpectedJsonMessageType = errors.New("unexpected json message type")
+
+// ParseJsonRecords reads the messages written by [JsonHandler] from reader, leaving the data
+// of each message to be decoded with [DecodeJsonLoggerCreated], [DecodeJsonLoggerClosed] or
+// [DecodeJsonRecord] depending on its type. Both NDJSON and array framing are accepted.
+// Iteration stops after the first error.
+func ParseJsonRecords(reader io.Reader) iter.Seq2[JsonHandlerMessage[json.RawMessage], error] {
+	return func(yield func(JsonHandlerMessage[json.RawMessage], error) bool) {
+		buffered := bufio.NewReader(reader)
+		decoder := json.NewDecoder(buffered)
+
+		if isJsonArray(buffered) {
+			if _, err := decoder.Token(); err != nil {
+				yield(JsonHandlerMessage[json.RawMessage]{}, err)
+				return
+			}
+
+			for decoder.More() {
+				var message JsonHandlerMessage[json.RawMessage]
+
+				err := decoder.Decode(&message)
+				if !yield(message, err) || err != nil {
+					return
+				}
+			}
+
+			if _, err := decoder.Token(); err != nil {
+				yield(JsonHandlerMessage[json.RawMessage]{}, err)
+			}
+
+			return
+		}
+
+		for {
+			var message JsonHandlerMessage[json.RawMessage]
+
+			err := decoder.Decode(&message)
+			if err == io.EOF {
+				return
+			}
+
+			if !yield(message, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// isJsonArray reports whether the first character other than whitespace is the start of an
+// array, without consuming it
+func isJsonArray(reader *bufio.Reader) bool {
+	for n := 1; ; n++ {
+		peeked, _ := reader.Peek(n)
+		if len(peeked) < n {
+			return false
+		}
+
+		switch peeked[n-1] {
+		case ' ', '\t', '\r', '\n':
+			continue
+		case '[':
+			return true
+		default:
+			return false
+		}
+	}
+}
+
+func decodeJsonData[T any](message JsonHandlerMessage[json.RawMessage], expected JsonHandlerMessageType) (T, error) {
+	var data T
+
+	if message.Type != expected {
+		return data, fmt.Errorf("%w: expected %d, got %d", ErrUnexpectedJsonMessageType, expected, message.Type)
+	}
+
+	err := json.Unmarshal(message.Data, &data)
+	return data, err
+}
+
+func DecodeJsonLoggerCreated(message JsonHandlerMessage[json.RawMessage]) (JsonHandlerLoggerCreated, error) {
+	return decodeJsonData[JsonHandlerLoggerCreated](message, JsonHandlerMessageType_LoggerCreated)
+}
+
+func DecodeJsonLoggerClosed(message JsonHandlerMessage[json.RawMessage]) (JsonHandlerLoggerClosed, error) {
+	return decodeJsonData[JsonHandlerLoggerClosed](message, JsonHandlerMessageType_LoggerClosed)
+}
+
+func DecodeJsonRecord(message JsonHandlerMessage[json.RawMessage]) (JsonHandlerRecord, error) {
+	return decodeJsonData[JsonHandlerRecord](message, JsonHandlerMessageType_Record)
+}
+
+// Record converts the decoded message back to a [Record]. Values that do not survive the
+// round trip through JSON, such as errors and durations, are returned as decoded by
+// [encoding/json].
+func (data JsonHandlerRecord) Record() (Record, error) {
+	level, err := ParseLevel(data.Level)
+	if err != nil {
+		return Record{}, err
+	}
+
+	var kind RecordKind
+	if data.Kind == RecordKind_Metric.String() {
+		kind = RecordKind_Metric
+	}
+
+	var caller *runtime.Frame
+	if data.Caller != (JsonHandlerCaller{}) {
+		caller = &runtime.Frame{
+			PC:       uintptr(data.Caller.PC),
+			Function: data.Caller.Function,
+			File:     data.Caller.File,
+			Line:     data.Caller.Line,
+		}
+	}
+
+	attrs := attrsFromJson(data.Attributes)
+	if data.Host != nil {
+		attrs = append(attrs, Attribute{Key: "hostname", Value: data.Host.Name})
+	}
+
+	if data.Process != nil {
+		attrs = append(attrs, Attribute{Key: "pid", Value: data.Process.Pid})
+	}
+
+	return Record{
+		Time:       data.Time,
+		Level:      level,
+		Kind:       kind,
+		Message:    data.Message,
+		Caller:     caller,
+		Attributes: attrs,
+		TraceId:    data.TraceId,
+		SpanId:     data.SpanId,
+	}, nil
+}
+
+func attrsFromJson(jsonAttrs JsonHandlerAttributes) []Attribute {
+	attrs := make([]Attribute, len(jsonAttrs))
+	for i, attr := range jsonAttrs {
+		if group, ok := attr.Value.(JsonHandlerAttributes); ok {
+			attrs[i] = Attribute{Key: attr.Key, Value: attrsFromJson(group)}
+		} else {
+			attrs[i] = Attribute{Key: attr.Key, Value: attr.Value}
+		}
+	}
+
+	return attrs
+}
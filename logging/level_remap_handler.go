@@ -0,0 +1,33 @@
+package logging
+
+import (
+	"runtime"
+	"time"
+)
+
+// LevelRemapHandler changes the level of each record with a remapping function before
+// delegating it to the wrapped handler, which then filters on the remapped level
+type LevelRemapHandler struct {
+	inner Handler
+	remap func(level Level, record Record) Level
+}
+
+func NewLevelRemapHandler(inner Handler, remap func(level Level, record Record) Level) *LevelRemapHandler {
+	return &LevelRemapHandler{inner: inner, remap: remap}
+}
+
+// Implements [logging.Handler]
+func (handler *LevelRemapHandler) OnLoggerCreated(logger *Logger, timestamp time.Time, caller *runtime.Frame) {
+	handler.inner.OnLoggerCreated(logger, timestamp, caller)
+}
+
+// Implements [logging.Handler]
+func (handler *LevelRemapHandler) OnLoggerClosed(logger *Logger, timestamp time.Time, caller *runtime.Frame) error {
+	return handler.inner.OnLoggerClosed(logger, timestamp, caller)
+}
+
+// Implements [logging.Handler]
+func (handler *LevelRemapHandler) HandleRecord(logger *Logger, record Record) error {
+	record.Level = handler.remap(record.Level, record)
+	return handler.inner.HandleRecord(logger, record)
+}
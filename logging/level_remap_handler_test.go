@@ -0,0 +1,39 @@
+package logging
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestLevelRemapHandler(t *testing.T) {
+	var got []string
+	inner := NewFuncHandler(LevelWarn, func(logger *Logger, record Record) error {
+		got = append(got, record.Level.String()+" "+record.Message)
+		return nil
+	})
+
+	remap := func(level Level, record Record) Level {
+		switch record.Message {
+		case "noisy":
+			return LevelError
+		case "third-party":
+			return LevelDebug
+		default:
+			return level
+		}
+	}
+
+	logger := NewLogger()
+	logger.AddHandler(NewLevelRemapHandler(inner, remap))
+
+	logger.Warn("noisy")
+	logger.Error("third-party")
+	logger.Warn("other")
+	logger.Info("info")
+
+	// The demoted record is dropped by the inner handler, which sees the remapped levels
+	want := []string{"error noisy", "warn other"}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
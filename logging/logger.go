@@ -1,76 +1,126 @@
 package logging
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"io"
 	"os"
+	"reflect"
 	"runtime"
+	"runtime/debug"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
 )
 
-func getModulePath(functionPath string) string {
-	// Module paths contain parens for the struct and have an additional dot in the path
-	// ex.
-	//  Module: github.com/link00000000/fredboard/v3/go-telemetry.(*Logger).Log
-	//  Function: ccidentallycoded.com/fredboard/v3/go-telemetry.NewLogger
-	isMethod := strings.Contains(functionPath, "(")
-
-	var endOfModuleName int
-	if isMethod {
-		endOfModuleName = strings.LastIndex(functionPath, "(") - 1
-	} else {
-		endOfModuleName = strings.LastIndex(functionPath, ".")
+// getPackagePath returns the import path of the package declaring the function, ex.
+// github.com/link00000000/go-telemetry/logging for
+// github.com/link00000000/go-telemetry/logging.(*Logger).Log
+func getPackagePath(functionPath string) string {
+	// Type parameters of generic functions may contain any path, ignore them
+	if i := strings.Index(functionPath, "["); i >= 0 {
+		functionPath = functionPath[:i]
 	}
 
-	if endOfModuleName == -1 {
-		panic("malformed module name does not contain a . delimiter")
+	lastSlash := strings.LastIndex(functionPath, "/")
+	dot := strings.Index(functionPath[lastSlash+1:], ".")
+	if dot < 0 {
+		return functionPath
 	}
 
-	return functionPath[:endOfModuleName]
+	return functionPath[:lastSlash+1+dot]
 }
 
 var ErrNoCaller = errors.New("no caller")
 
-func getCaller() (*runtime.Frame, error) {
-	pcs := make([]uintptr, 8)
-	n := runtime.Callers(1, pcs)
-	pcs = pcs[:n]
+// loggingPackage is the package path of this package, frames inside it are skipped when
+// looking for the caller
+var loggingPackage = reflect.TypeOf((*Logger)(nil)).Elem().PkgPath()
 
-	if len(pcs) == 0 {
-		return nil, ErrNoCaller
-	}
+var (
+	ignoredFramesMutex sync.RWMutex
+	ignoredFrames      []string
+)
 
-	frames := runtime.CallersFrames(pcs)
+// IgnoreFrames skips the frames of the packages with one of prefixes as their import path,
+// ex. github.com/gin-gonic/gin, and of the packages below them, when looking for the caller of
+// records and logger events, so that the caller is the nearest frame outside of this package
+// and of the ignored packages. Prefixes match whole path elements, ignoring
+// github.com/gin-gonic/gin does not ignore github.com/gin-gonic/ginkgo.
+// The prefixes are added to those of earlier calls and apply to every logger.
+func IgnoreFrames(prefixes ...string) {
+	ignoredFramesMutex.Lock()
+	defer ignoredFramesMutex.Unlock()
+
+	ignoredFrames = append(slices.Clip(ignoredFrames), prefixes...)
+}
 
-	firstFrame, more := frames.Next()
-	if !more {
-		return nil, ErrNoCaller
+// isIgnoredPackage reports whether the frames of pkg are skipped when looking for the caller
+func isIgnoredPackage(pkg string, ignored []string) bool {
+	if pkg == loggingPackage || pkg == "runtime" {
+		return true
 	}
 
-	thisModule := getModulePath(firstFrame.Function)
+	for _, prefix := range ignored {
+		if pkg == prefix || strings.HasPrefix(pkg, prefix+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getCaller returns the first frame outside of this package, the runtime and the packages
+// ignored with [IgnoreFrames]. The runtime appears between a deferred function and the code
+// that panicked. Frames are expanded with [runtime.CallersFrames] so that functions inlined
+// into their callers are still recognized.
+func getCaller() (*runtime.Frame, error) {
+	ignoredFramesMutex.RLock()
+	ignored := ignoredFrames
+	ignoredFramesMutex.RUnlock()
+
+	pcs := make([]uintptr, 32)
+	skip := 2
 
 	for {
-		frame, more := frames.Next()
-		module := getModulePath(frame.Function)
+		n := runtime.Callers(skip, pcs)
+		if n == 0 {
+			return nil, ErrNoCaller
+		}
+
+		frames := runtime.CallersFrames(pcs[:n])
+		for {
+			frame, more := frames.Next()
+			if frame.Function != "" && !isIgnoredPackage(getPackagePath(frame.Function), ignored) {
+				return &frame, nil
+			}
 
-		if module != thisModule {
-			return &frame, nil
+			if !more {
+				break
+			}
 		}
 
-		if !more {
-			break
+		// Every frame so far was skipped, look further up the stack
+		if n < len(pcs) {
+			return nil, ErrNoCaller
 		}
-	}
 
-	return nil, ErrNoCaller
+		skip += n
+	}
 }
 
 type Level int
 
+// LevelTrace was added below LevelDebug after the other levels, so it is negative and the
+// numeric values of the existing levels are unchanged
 const (
-	LevelDebug Level = iota
+	LevelTrace Level = iota - 1
+	LevelDebug
 	LevelInfo
 	LevelWarn
 	LevelError
@@ -78,6 +128,60 @@ const (
 	LevelPanic
 )
 
+func (level Level) String() string {
+	switch level {
+	case LevelTrace:
+		return "trace"
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	case LevelFatal:
+		return "fatal"
+	case LevelPanic:
+		return "panic"
+	default:
+		return fmt.Sprintf("level(%d)", int(level))
+	}
+}
+
+var ErrUnknownLevel = errors.New("unknown level")
+
+// ParseLevel returns the level named by s, as formatted by [Level.String]
+func ParseLevel(s string) (Level, error) {
+	for level := LevelTrace; level <= LevelPanic; level++ {
+		if level.String() == s {
+			return level, nil
+		}
+	}
+
+	return 0, fmt.Errorf("%w: %q", ErrUnknownLevel, s)
+}
+
+// RecordKind separates records carrying metrics from ordinary log records sharing the same
+// handlers
+type RecordKind int
+
+const (
+	RecordKind_Log RecordKind = iota
+	RecordKind_Metric
+)
+
+func (kind RecordKind) String() string {
+	switch kind {
+	case RecordKind_Log:
+		return "log"
+	case RecordKind_Metric:
+		return "metric"
+	default:
+		return fmt.Sprintf("kind(%d)", int(kind))
+	}
+}
+
 type LoggerState int
 
 const (
@@ -88,9 +192,21 @@ const (
 type Record struct {
 	Time       time.Time
 	Level      Level
+	Kind       RecordKind
 	Message    string
 	Caller     *runtime.Frame
 	Attributes []Attribute
+
+	// Package path of the caller, ex. github.com/link00000000/go-telemetry/logging. Empty
+	// when the caller is unknown.
+	CallerModule string
+
+	// Distributed tracing identifiers, empty when the record is not part of a trace
+	TraceId string
+	SpanId  string
+
+	// Forwarding handlers the record has passed through, to break forwarding loops
+	forwardedBy []*ForwardingHandler
 }
 
 type Attribute struct {
@@ -104,40 +220,197 @@ type Handler interface {
 	HandleRecord(logger *Logger, record Record) error
 }
 
+// Formatter creates a handler that renders records to writer
+type Formatter func(writer io.Writer) Handler
+
+func JsonFormatter(writer io.Writer) Handler {
+	return NewJsonHandler(writer, LevelTrace)
+}
+
+func PrettyFormatter(writer io.Writer) Handler {
+	return NewPrettyHandler(writer, LevelTrace)
+}
+
+// Flusher is implemented by handlers that buffer their output
+type Flusher interface {
+	Flush() error
+}
+
+// sameHandler reports whether a and b are the same handler, which is pointer identity for
+// pointer handlers and equality for value handlers. Handlers of types that cannot be
+// compared are never considered the same.
+func sameHandler(a Handler, b Handler) bool {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if va.Type() != vb.Type() || !va.Comparable() || !vb.Comparable() {
+		return false
+	}
+
+	return a == b
+}
+
+// BatchHandler is implemented by handlers that can process several records of the same
+// logger at once more efficiently than one at a time, see [Logger.LogBatch]
+type BatchHandler interface {
+	HandleRecords(logger *Logger, records []Record) error
+}
+
+// LeveledHandler is implemented by handlers that drop records below a minimum level
+type LeveledHandler interface {
+	Handler
+	Level() Level
+}
+
+// handlerLevel returns the minimum level accepted by handler, which is the lowest level for
+// handlers that do not implement [LeveledHandler]
+func handlerLevel(handler Handler) Level {
+	if leveled, ok := handler.(LeveledHandler); ok {
+		return leveled.Level()
+	}
+
+	return LevelTrace
+}
+
 type Logger struct {
+	mutex sync.RWMutex
+
 	id       uuid.UUID
 	parent   *Logger
 	children []*Logger
 
-	state LoggerState
+	state     LoggerState
+	createdAt time.Time
+
+	// Indexed from LevelTrace, records of other levels are not counted
+	recordCounts [LevelPanic - LevelTrace + 1]atomic.Uint64
+
+	emitElapsed bool
+
+	minLevel    Level
+	hasMinLevel bool
 
-	panicOnError bool
-	handlers     []Handler
+	traceId string
+	spanId  string
+
+	// Added to every record logged through this logger or its descendants
+	attrs []Attribute
+
+	panicOnError  bool
+	skipCaller    bool
+	monotonicTime bool
+	strictAttrs   bool
+	exitCode      int
+
+	callerLevel    Level
+	hasCallerLevel bool
+
+	emitGoroutine bool
+	emitHostname  bool
+	emitPid       bool
+	errorHandler  func(handler Handler, err error)
+	handlers      []Handler
+
+	redactedKeys map[string]struct{}
+	redactors    []redactor
+
+	processors []func(record *Record)
+}
+
+var (
+	idGeneratorMutex sync.RWMutex
+	idGenerator      = uuid.New
+)
+
+// SetIDGenerator replaces the function generating the IDs of new loggers, for example with a
+// deterministic sequence in tests. Passing nil restores the default of [uuid.New].
+func SetIDGenerator(fn func() uuid.UUID) {
+	if fn == nil {
+		fn = uuid.New
+	}
+
+	idGeneratorMutex.Lock()
+	defer idGeneratorMutex.Unlock()
+
+	idGenerator = fn
+}
+
+func newLoggerId() uuid.UUID {
+	idGeneratorMutex.RLock()
+	defer idGeneratorMutex.RUnlock()
+
+	return idGenerator()
 }
 
 func NewLogger() *Logger {
 	return &Logger{
-		id:       uuid.New(),
-		children: make([]*Logger, 0),
-		state:    LoggerState_Open,
-		handlers: make([]Handler, 0),
+		id:        newLoggerId(),
+		children:  make([]*Logger, 0),
+		state:     LoggerState_Open,
+		createdAt: time.Now(),
+		handlers:  make([]Handler, 0),
 	}
 }
 
+// NewNopLogger creates a logger that discards all records
+func NewNopLogger() *Logger {
+	logger := NewLogger()
+	logger.AddHandler(NewDiscardHandler())
+
+	return logger
+}
+
+var (
+	defaultLogger     *Logger
+	defaultLoggerOnce sync.Once
+)
+
+// Default returns a shared logger that pretty prints records at [LevelInfo] and above to
+// stderr. It is created on first use.
+func Default() *Logger {
+	defaultLoggerOnce.Do(func() {
+		defaultLogger = NewLogger()
+		defaultLogger.AddHandler(NewPrettyHandler(os.Stderr, LevelInfo))
+	})
+
+	return defaultLogger
+}
+
+var ErrLoggerClosed = errors.New("logger closed")
+
+// IsClosed reports whether the logger has been closed, either directly or by closing one of
+// its ancestors
+func (logger *Logger) IsClosed() bool {
+	logger.mutex.RLock()
+	defer logger.mutex.RUnlock()
+
+	return logger.state == LoggerState_Closed
+}
+
+// NewChildLogger creates a logger below this one in the tree. If this logger is closed, the
+// child is returned already closed and the handlers are not notified.
 func (logger *Logger) NewChildLogger() *Logger {
 	childLogger := NewLogger()
 	childLogger.parent = logger
 
+	logger.mutex.Lock()
+	if logger.state == LoggerState_Closed {
+		logger.mutex.Unlock()
+
+		// The child would never be closed by its parent, hand out one that is already closed
+		childLogger.state = LoggerState_Closed
+		return childLogger
+	}
+
 	logger.children = append(logger.children, childLogger)
+	logger.mutex.Unlock()
 
-	caller, err := getCaller()
+	caller, err := logger.caller()
 
 	// Ignore ErrNoCaller and continue to log without the caller
 	if err != nil && err != ErrNoCaller {
 		panic(err)
 	}
 
-	now := time.Now().UTC()
+	now := logger.timestamp(childLogger.createdAt)
 	for _, handler := range childLogger.Handlers() {
 		handler.OnLoggerCreated(childLogger, now, caller)
 	}
@@ -145,39 +418,319 @@ func (logger *Logger) NewChildLogger() *Logger {
 	return childLogger
 }
 
-// Implements [io.Closer]
+// Clone creates a new root logger with the same handlers and settings as the logger,
+// including those it inherits from its ancestors. Unlike [Logger.NewChildLogger], the clone
+// is not linked into the tree: it has no parent, is not reported to the handlers as
+// created and is not closed when the logger is closed. Later changes to either logger do
+// not affect the other.
+func (logger *Logger) Clone() *Logger {
+	clone := NewLogger()
+	clone.handlers = logger.Handlers()
+	clone.minLevel, clone.hasMinLevel = logger.MinLevel()
+	clone.traceId, clone.spanId = logger.trace()
+	clone.attrs = logger.withInheritedAttrs(nil)
+
+	logger.mutex.RLock()
+	clone.emitElapsed = logger.emitElapsed
+	logger.mutex.RUnlock()
+
+	root := logger.RootLogger()
+
+	root.mutex.RLock()
+	clone.panicOnError = root.panicOnError
+	clone.skipCaller = root.skipCaller
+	clone.monotonicTime = root.monotonicTime
+	clone.strictAttrs = root.strictAttrs
+	clone.exitCode = root.exitCode
+	clone.callerLevel, clone.hasCallerLevel = root.callerLevel, root.hasCallerLevel
+	clone.emitGoroutine = root.emitGoroutine
+	clone.emitHostname, clone.emitPid = root.emitHostname, root.emitPid
+	clone.errorHandler = root.errorHandler
+	clone.redactedKeys = root.redactedKeys
+	clone.redactors = slices.Clip(root.redactors)
+	clone.processors = slices.Clip(root.processors)
+	root.mutex.RUnlock()
+
+	return clone
+}
+
+// SetMinLevel drops records below level logged through this logger before they reach the
+// handlers, which still apply their own levels. Child loggers inherit the minimum level of
+// their nearest ancestor that has one unless they set their own.
+func (logger *Logger) SetMinLevel(level Level) {
+	logger.mutex.Lock()
+	defer logger.mutex.Unlock()
+
+	logger.minLevel = level
+	logger.hasMinLevel = true
+}
+
+// MinLevel returns the minimum level set on this logger or inherited from its ancestors. ok
+// is false if no minimum level has been set.
+func (logger *Logger) MinLevel() (level Level, ok bool) {
+	for l := logger; l != nil; l = l.parent {
+		l.mutex.RLock()
+		level, ok = l.minLevel, l.hasMinLevel
+		l.mutex.RUnlock()
+
+		if ok {
+			return level, true
+		}
+	}
+
+	return level, false
+}
+
+// EmitElapsed controls whether records logged directly through this logger carry an
+// "elapsed" attribute with the time since the logger was created
+func (logger *Logger) EmitElapsed(value bool) {
+	logger.mutex.Lock()
+	defer logger.mutex.Unlock()
+
+	logger.emitElapsed = value
+}
+
+// WithTrace creates a child logger that stamps traceId and spanId on every record logged
+// through it or its descendants
+func (logger *Logger) WithTrace(traceId string, spanId string) *Logger {
+	childLogger := logger.NewChildLogger()
+
+	childLogger.mutex.Lock()
+	childLogger.traceId = traceId
+	childLogger.spanId = spanId
+	childLogger.mutex.Unlock()
+
+	return childLogger
+}
+
+// WithAttributes creates a child logger that adds args, as alternating keys and values, to
+// every record logged through it or its descendants. Records carry the attributes of the
+// ancestors first, from the root down to the nearest logger, followed by the attributes of
+// the call. When a key is repeated, only the attribute set nearest to the call is kept, so
+// attributes of the call replace those of the loggers and those of a child replace those of
+// its ancestors.
+func (logger *Logger) WithAttributes(args ...any) *Logger {
+	childLogger := logger.NewChildLogger()
+
+	childLogger.mutex.Lock()
+	childLogger.attrs = argsToAttrs(args)
+	childLogger.mutex.Unlock()
+
+	return childLogger
+}
+
+// withInheritedAttrs returns the attributes of the logger and its ancestors merged with
+// attrs as documented by [Logger.WithAttributes]
+func (logger *Logger) withInheritedAttrs(attrs []Attribute) []Attribute {
+	// Nearest logger first
+	chain := make([][]Attribute, 0)
+	for l := logger; l != nil; l = l.parent {
+		l.mutex.RLock()
+		if len(l.attrs) > 0 {
+			chain = append(chain, l.attrs)
+		}
+		l.mutex.RUnlock()
+	}
+
+	if len(chain) == 0 {
+		return attrs
+	}
+
+	seen := make(map[string]struct{}, len(attrs))
+	for _, attr := range attrs {
+		seen[attr.Key] = struct{}{}
+	}
+
+	kept := make([][]Attribute, len(chain))
+	for i, loggerAttrs := range chain {
+		for _, attr := range loggerAttrs {
+			if _, ok := seen[attr.Key]; !ok {
+				kept[i] = append(kept[i], attr)
+			}
+		}
+
+		for _, attr := range loggerAttrs {
+			seen[attr.Key] = struct{}{}
+		}
+	}
+
+	merged := make([]Attribute, 0, len(attrs))
+	for _, loggerAttrs := range slices.Backward(kept) {
+		merged = append(merged, loggerAttrs...)
+	}
+
+	return append(merged, attrs...)
+}
+
+// trace returns the tracing identifiers of the nearest logger in the parent chain that has
+// them set
+func (logger *Logger) trace() (traceId string, spanId string) {
+	for l := logger; l != nil; l = l.parent {
+		l.mutex.RLock()
+		traceId, spanId = l.traceId, l.spanId
+		l.mutex.RUnlock()
+
+		if traceId != "" || spanId != "" {
+			return traceId, spanId
+		}
+	}
+
+	return "", ""
+}
+
+// ContextCloser is implemented by handlers that need to release resources when the logger
+// they are attached to is closed with [Logger.CloseContext], such as network handlers
+// flushing buffered records. Handlers should give up once ctx is done.
+type ContextCloser interface {
+	CloseContext(ctx context.Context) error
+}
+
+// Implements [io.Closer]. Same as [Logger.CloseContext] without a deadline.
 func (logger *Logger) Close() error {
+	return logger.CloseContext(context.Background())
+}
+
+// CloseContext closes the logger and its descendants, and then closes the handlers
+// attached to each of them that implement [ContextCloser] with ctx, so that shutdown is
+// bounded by the deadline of ctx
+func (logger *Logger) CloseContext(ctx context.Context) error {
+	logger.mutex.Lock()
+
 	// Prevent closing a logger multiple times
 	if logger.state == LoggerState_Closed {
+		logger.mutex.Unlock()
 		return nil
 	}
 
+	// Mark the logger closed before closing the children so that a child closing its parent
+	// cannot invoke the handlers a second time
+	logger.state = LoggerState_Closed
+	children := slices.Clone(logger.children)
+
+	logger.mutex.Unlock()
+
 	errs := make([]error, 0)
 
-	for _, child := range logger.children {
-		err := child.Close()
+	for _, child := range children {
+		err := child.CloseContext(ctx)
 		if err != nil {
 			errs = append(errs, err)
 		}
 	}
 
-	caller, err := getCaller()
+	caller, err := logger.caller()
 
 	// Ignore ErrNoCaller and continue to log without the caller
 	if err != nil && err != ErrNoCaller {
 		return err
 	}
 
-	now := time.Now().UTC()
+	now := logger.timestamp(time.Now())
 	for _, handler := range logger.Handlers() {
-		errs = append(errs, handler.OnLoggerClosed(logger, now, caller))
+		if err := handler.OnLoggerClosed(logger, now, caller); err != nil {
+			logger.reportHandlerError(handler, err)
+			errs = append(errs, fmt.Errorf("logger %s: %w", logger.id, err))
+		}
 	}
 
-	logger.state = LoggerState_Closed
+	logger.mutex.RLock()
+	handlers := slices.Clone(logger.handlers)
+	logger.mutex.RUnlock()
+
+	for _, handler := range handlers {
+		if closer, ok := handler.(ContextCloser); ok {
+			if err := closer.CloseContext(ctx); err != nil {
+				logger.reportHandlerError(handler, err)
+				errs = append(errs, fmt.Errorf("logger %s: %w", logger.id, err))
+			}
+		}
+	}
 
 	return errors.Join(errs...)
 }
 
+// Walk calls fn for the logger and each of its descendants in depth first order, with the
+// depth relative to this logger. The walk stops as soon as fn returns false.
+func (logger *Logger) Walk(fn func(l *Logger, depth int) bool) {
+	logger.walk(fn, 0)
+}
+
+func (logger *Logger) walk(fn func(l *Logger, depth int) bool, depth int) bool {
+	if !fn(logger, depth) {
+		return false
+	}
+
+	for _, child := range logger.childLoggers() {
+		if !child.walk(fn, depth+1) {
+			return false
+		}
+	}
+
+	return true
+}
+
+var ErrAdoptCycle = errors.New("adopting logger would create a cycle")
+
+// Adopt moves child and its descendants below the logger, detaching it from its current
+// parent if it has one. Afterwards, records of child reach the handlers of the logger and
+// its ancestors, which are notified of the adopted loggers as if they had just been created,
+// and the settings of the logger's root apply to child. The settings and handlers of child
+// itself are kept. Adopt must not be called while child is in use by other goroutines.
+func (logger *Logger) Adopt(child *Logger) error {
+	for l := logger; l != nil; l = l.parent {
+		if l == child {
+			return ErrAdoptCycle
+		}
+	}
+
+	if child.IsClosed() {
+		return ErrLoggerClosed
+	}
+
+	if oldParent := child.parent; oldParent != nil {
+		if oldParent == logger {
+			return nil
+		}
+
+		oldParent.mutex.Lock()
+		oldParent.children = slices.DeleteFunc(oldParent.children, func(c *Logger) bool { return c == child })
+		oldParent.mutex.Unlock()
+	}
+
+	logger.mutex.Lock()
+	if logger.state == LoggerState_Closed {
+		logger.mutex.Unlock()
+		return ErrLoggerClosed
+	}
+
+	logger.children = append(logger.children, child)
+	logger.mutex.Unlock()
+
+	child.mutex.Lock()
+	child.parent = logger
+	child.mutex.Unlock()
+
+	caller, err := logger.caller()
+
+	// Ignore ErrNoCaller and continue without the caller
+	if err != nil && !errors.Is(err, ErrNoCaller) {
+		return err
+	}
+
+	handlers := logger.Handlers()
+	now := logger.timestamp(time.Now())
+
+	child.Walk(func(l *Logger, depth int) bool {
+		for _, handler := range handlers {
+			handler.OnLoggerCreated(l, now, caller)
+		}
+
+		return true
+	})
+
+	return nil
+}
+
 func (logger *Logger) RootLogger() *Logger {
 	l := logger
 
@@ -188,46 +741,545 @@ func (logger *Logger) RootLogger() *Logger {
 	return l
 }
 
+// childLoggers returns a copy of the direct children of the logger
+func (logger *Logger) childLoggers() []*Logger {
+	logger.mutex.RLock()
+	defer logger.mutex.RUnlock()
+
+	return slices.Clone(logger.children)
+}
+
+// Handlers returns the handlers that receive the records of the logger: those added to the
+// root logger first, followed by those of each descendant down to the logger itself
 func (logger *Logger) Handlers() []Handler {
-	return logger.RootLogger().handlers
+	chain := make([]*Logger, 0)
+	for l := logger; l != nil; l = l.parent {
+		chain = append(chain, l)
+	}
+
+	handlers := make([]Handler, 0)
+	for _, l := range slices.Backward(chain) {
+		l.mutex.RLock()
+		handlers = append(handlers, l.handlers...)
+		l.mutex.RUnlock()
+	}
+
+	return handlers
 }
 
+// AddHandler attaches handler to the logger, so that it receives the records of the logger
+// and of all of its descendants. Adding a handler that is already attached to the logger or
+// one of its ancestors does nothing, so that records are not handled twice.
+//
+// Pointer handlers are the same handler only if they are the same pointer. Handlers that are
+// values, such as [PrettyHandler], are compared by value, so a separately created handler
+// with the same writer and settings as an attached one is a duplicate and is not added.
+// Handlers of types that cannot be compared are never duplicates.
 func (logger *Logger) AddHandler(handler Handler) {
-	logger.RootLogger().handlers = append(logger.RootLogger().handlers, handler)
+	for l := logger.parent; l != nil; l = l.parent {
+		l.mutex.RLock()
+		attached := slices.ContainsFunc(l.handlers, func(h Handler) bool { return sameHandler(h, handler) })
+		l.mutex.RUnlock()
+
+		if attached {
+			return
+		}
+	}
+
+	logger.mutex.Lock()
+	defer logger.mutex.Unlock()
+
+	if slices.ContainsFunc(logger.handlers, func(h Handler) bool { return sameHandler(h, handler) }) {
+		return
+	}
+
+	logger.handlers = append(logger.handlers, handler)
+}
+
+// RemoveHandler detaches handler from the logger it was added to with
+// [Logger.AddHandler] and flushes it if it implements [Flusher]. It reports whether the
+// handler was found. Handlers are matched like in [Logger.AddHandler], so a value handler
+// can be removed with an equal copy. OnLoggerClosed is not called because the loggers
+// themselves remain open.
+func (logger *Logger) RemoveHandler(handler Handler) bool {
+	logger.mutex.Lock()
+	i := slices.IndexFunc(logger.handlers, func(h Handler) bool { return sameHandler(h, handler) })
+	if i != -1 {
+		logger.handlers = slices.Delete(logger.handlers, i, i+1)
+	}
+	logger.mutex.Unlock()
+
+	if i == -1 {
+		return false
+	}
+
+	if flusher, ok := handler.(Flusher); ok {
+		flusher.Flush()
+	}
+
+	return true
 }
 
 func (logger *Logger) PanicOnError() bool {
-	return logger.RootLogger().panicOnError
+	root := logger.RootLogger()
+
+	root.mutex.RLock()
+	defer root.mutex.RUnlock()
+
+	return root.panicOnError
 }
 
 func (logger *Logger) SetPanicOnError(value bool) {
-	logger.RootLogger().panicOnError = value
+	root := logger.RootLogger()
+
+	root.mutex.Lock()
+	defer root.mutex.Unlock()
+
+	root.panicOnError = value
+}
+
+// SetCaptureCaller controls whether records and logger events carry the frame of the code
+// that logged them. Looking up the caller walks the stack on every call, disabling it
+// reduces the cost of logging at the expense of handlers showing an unknown caller.
+// Capturing is enabled by default and applies to the whole tree.
+func (logger *Logger) SetCaptureCaller(value bool) {
+	root := logger.RootLogger()
+
+	root.mutex.Lock()
+	defer root.mutex.Unlock()
+
+	root.skipCaller = !value
+}
+
+// EmitGoroutineID controls whether records carry a "goroutine" attribute with the ID of the
+// goroutine that logged them. The ID is parsed from a stack trace on every record, which
+// makes logging noticeably slower. Disabled by default and applies to the whole tree.
+func (logger *Logger) EmitGoroutineID(value bool) {
+	root := logger.RootLogger()
+
+	root.mutex.Lock()
+	defer root.mutex.Unlock()
+
+	root.emitGoroutine = value
+}
+
+// EmitHostname controls whether records carry a "hostname" attribute with the name of the
+// machine, which is looked up once. [JsonHandler] writes it in the host object instead of
+// the attributes. Disabled by default and applies to the whole tree.
+func (logger *Logger) EmitHostname(value bool) {
+	root := logger.RootLogger()
+
+	root.mutex.Lock()
+	defer root.mutex.Unlock()
+
+	root.emitHostname = value
+}
+
+// EmitPID controls whether records carry a "pid" attribute with the ID of the process.
+// [JsonHandler] writes it in the process object instead of the attributes. Disabled by
+// default and applies to the whole tree.
+func (logger *Logger) EmitPID(value bool) {
+	root := logger.RootLogger()
+
+	root.mutex.Lock()
+	defer root.mutex.Unlock()
+
+	root.emitPid = value
+}
+
+// emitsProcess reports whether records carry the hostname and pid attributes
+func (logger *Logger) emitsProcess() (hostname bool, pid bool) {
+	root := logger.RootLogger()
+
+	root.mutex.RLock()
+	defer root.mutex.RUnlock()
+
+	return root.emitHostname, root.emitPid
+}
+
+// SetStrictAttrs controls whether [Logger.Log] and the functions built on it check that
+// their arguments pair up into keys and values. Records are still logged with !BADKEY
+// attributes, but an error wrapping [ErrBadAttrArgs] and naming the call site is returned,
+// which panics if [Logger.SetPanicOnError] is enabled. Disabled by default and applies to
+// the whole tree.
+func (logger *Logger) SetStrictAttrs(value bool) {
+	root := logger.RootLogger()
+
+	root.mutex.Lock()
+	defer root.mutex.Unlock()
+
+	root.strictAttrs = value
+}
+
+// SetMonotonicTime controls whether the times passed to the handlers keep the monotonic
+// clock reading, so that durations between records are not affected by changes to the wall
+// clock. Otherwise times are converted to UTC, which strips the reading. Handlers format
+// times in UTC either way. Disabled by default and applies to the whole tree.
+func (logger *Logger) SetMonotonicTime(value bool) {
+	root := logger.RootLogger()
+
+	root.mutex.Lock()
+	defer root.mutex.Unlock()
+
+	root.monotonicTime = value
+}
+
+// timestamp prepares t to be passed to the handlers according to [Logger.SetMonotonicTime]
+func (logger *Logger) timestamp(t time.Time) time.Time {
+	root := logger.RootLogger()
+
+	root.mutex.RLock()
+	monotonic := root.monotonicTime
+	root.mutex.RUnlock()
+
+	if monotonic {
+		return t
+	}
+
+	return t.UTC()
+}
+
+// SetCallerLevel limits caller capture to records at level or above, records below it have a
+// nil caller. Logger lifecycle events are not affected. Applies to the whole tree, and has no
+// effect while capture is disabled with [Logger.SetCaptureCaller].
+func (logger *Logger) SetCallerLevel(level Level) {
+	root := logger.RootLogger()
+
+	root.mutex.Lock()
+	defer root.mutex.Unlock()
+
+	root.callerLevel = level
+	root.hasCallerLevel = true
+}
+
+// caller returns the caller of the logging function, or nil if caller capture is disabled
+func (logger *Logger) caller() (*runtime.Frame, error) {
+	root := logger.RootLogger()
+
+	root.mutex.RLock()
+	skip := root.skipCaller
+	root.mutex.RUnlock()
+
+	if skip {
+		return nil, nil
+	}
+
+	return getCaller()
+}
+
+// recordCaller is like [Logger.caller] for a record at level, see [Logger.SetCallerLevel]
+func (logger *Logger) recordCaller(level Level) (*runtime.Frame, error) {
+	root := logger.RootLogger()
+
+	root.mutex.RLock()
+	below := root.hasCallerLevel && level < root.callerLevel
+	root.mutex.RUnlock()
+
+	if below {
+		return nil, nil
+	}
+
+	return logger.caller()
+}
+
+// SetErrorHandler registers fn to be called with every error returned by a handler while
+// logging or closing, in the order the handlers were added. The errors are still returned.
+func (logger *Logger) SetErrorHandler(fn func(handler Handler, err error)) {
+	root := logger.RootLogger()
+
+	root.mutex.Lock()
+	defer root.mutex.Unlock()
+
+	root.errorHandler = fn
+}
+
+func (logger *Logger) reportHandlerError(handler Handler, err error) {
+	root := logger.RootLogger()
+
+	root.mutex.RLock()
+	fn := root.errorHandler
+	root.mutex.RUnlock()
+
+	if fn != nil {
+		fn(handler, err)
+	}
+}
+
+// Enabled reports whether any handler would accept a record at the given level.
+// Handlers that do not implement [LeveledHandler] are assumed to accept every level.
+func (logger *Logger) Enabled(level Level) bool {
+	if minLevel, ok := logger.MinLevel(); ok && level < minLevel {
+		return false
+	}
+
+	minLevel, ok := logger.minHandlerLevel()
+	return ok && level >= minLevel
 }
 
+// minHandlerLevel returns the lowest level accepted by any handler. ok is false when
+// there are no handlers, in which case no level is accepted.
+func (logger *Logger) minHandlerLevel() (minLevel Level, ok bool) {
+	// Walk the chain instead of collecting [Logger.Handlers], so that checking a level does
+	// not allocate
+	for l := logger; l != nil; l = l.parent {
+		l.mutex.RLock()
+		for _, handler := range l.handlers {
+			if level := handlerLevel(handler); !ok || level < minLevel {
+				minLevel = level
+			}
+
+			ok = true
+		}
+		l.mutex.RUnlock()
+	}
+
+	return minLevel, ok
+}
+
+// Log sends a record to the handlers of the logger, with args as alternating keys and
+// values. Once the logger is closed, records are dropped and [ErrLoggerClosed] is returned.
 func (logger *Logger) Log(level Level, message string, args ...any) error {
-	caller, err := getCaller()
+	return logger.log(time.Time{}, level, message, args)
+}
+
+// LogAt is like [Logger.Log] but uses t as the time of the record instead of the current
+// time, for example when replaying events that happened earlier
+func (logger *Logger) LogAt(t time.Time, level Level, message string, args ...any) error {
+	return logger.log(t, level, message, args)
+}
+
+// log logs a record at t, or at the current time if t is zero
+func (logger *Logger) log(t time.Time, level Level, message string, args []any) error {
+	root := logger.RootLogger()
+
+	root.mutex.RLock()
+	strict := root.strictAttrs
+	root.mutex.RUnlock()
+
+	if !strict {
+		return logger.logAttrs(RecordKind_Log, t, level, message, argsToAttrs(args))
+	}
+
+	argsErr := checkArgs(args)
+	if argsErr != nil {
+		if caller, _ := logger.caller(); caller != nil {
+			argsErr = fmt.Errorf("%w at %s:%d", argsErr, caller.File, caller.Line)
+		}
+	}
+
+	return errors.Join(argsErr, logger.logAttrs(RecordKind_Log, t, level, message, argsToAttrs(args)))
+}
+
+// LogAttrs is like [Logger.Log] but takes already constructed attributes instead of
+// alternating keys and values
+func (logger *Logger) LogAttrs(level Level, message string, attrs ...Attribute) error {
+	return logger.logAttrs(RecordKind_Log, time.Time{}, level, message, attrs)
+}
+
+// Metric logs a [RecordKind_Metric] record at [LevelInfo] named name, with value in the
+// "value" attribute followed by tags as alternating keys and values
+func (logger *Logger) Metric(name string, value float64, tags ...any) error {
+	attrs := append([]Attribute{{Key: "value", Value: value}}, argsToAttrs(tags)...)
+
+	err := logger.logAttrs(RecordKind_Metric, time.Time{}, LevelInfo, name, attrs)
+	if err != nil && logger.PanicOnError() {
+		panic(err)
+	}
+
+	return err
+}
+
+// logAttrs logs a record of kind at t, or at the current time if t is zero
+func (logger *Logger) logAttrs(kind RecordKind, t time.Time, level Level, message string, attrs []Attribute) error {
+	if logger.IsClosed() {
+		return ErrLoggerClosed
+	}
+
+	if minLevel, ok := logger.MinLevel(); ok && level < minLevel {
+		return nil
+	}
+
+	caller, err := logger.recordCaller(level)
 
 	// Ignore ErrNoCaller and continue to log without the caller
 	if err != nil && !errors.Is(err, ErrNoCaller) {
 		return err
 	}
 
-	record := Record{
-		Time:       time.Now().UTC(),
+	if t.IsZero() {
+		t = time.Now()
+	}
+
+	record := logger.prepareRecord(Record{
+		Time:       logger.timestamp(t),
 		Level:      level,
+		Kind:       kind,
 		Message:    message,
 		Caller:     caller,
-		Attributes: argsToAttrs(args),
+		Attributes: attrs,
+	})
+
+	errs := make([]error, 0)
+	for _, handler := range logger.Handlers() {
+		if err := handler.HandleRecord(logger, record); err != nil {
+			logger.reportHandlerError(handler, err)
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// prepareRecord counts record and adds the fields derived from the logger before it is
+// passed to the handlers
+func (logger *Logger) prepareRecord(record Record) Record {
+	if record.Level >= LevelTrace && record.Level <= LevelPanic {
+		logger.recordCounts[record.Level-LevelTrace].Add(1)
+	}
+
+	if record.Caller != nil && record.CallerModule == "" {
+		record.CallerModule = getPackagePath(record.Caller.Function)
+	}
+
+	if record.TraceId == "" && record.SpanId == "" {
+		record.TraceId, record.SpanId = logger.trace()
+	}
+
+	record.Attributes = logger.withInheritedAttrs(record.Attributes)
+
+	logger.mutex.RLock()
+	if logger.emitElapsed {
+		record.Attributes = append(slices.Clip(record.Attributes), Attribute{Key: "elapsed", Value: time.Since(logger.createdAt)})
+	}
+	logger.mutex.RUnlock()
+
+	root := logger.RootLogger()
+
+	root.mutex.RLock()
+	emitGoroutine, emitHostname, emitPid := root.emitGoroutine, root.emitHostname, root.emitPid
+	processors := root.processors
+	root.mutex.RUnlock()
+
+	if emitGoroutine {
+		record.Attributes = append(slices.Clip(record.Attributes), Attribute{Key: "goroutine", Value: goroutineId()})
+	}
+
+	if emitHostname {
+		record.Attributes = append(slices.Clip(record.Attributes), Attribute{Key: "hostname", Value: processHostname()})
+	}
+
+	if emitPid {
+		record.Attributes = append(slices.Clip(record.Attributes), Attribute{Key: "pid", Value: processId})
+	}
+
+	if len(processors) > 0 {
+		// Processors may modify the attributes in place, which must not change those of the
+		// caller
+		record.Attributes = slices.Clone(record.Attributes)
+		for _, fn := range processors {
+			fn(&record)
+		}
+	}
+
+	// Redact last so that attributes added by processors are covered
+	record.Attributes = logger.redact(record.Attributes)
+
+	return record
+}
+
+// AddProcessor registers fn to modify every record before it reaches the handlers, for
+// example to add, rename or drop attributes. Processors run in the order they were added,
+// after the logger has added its own attributes and before redaction. Like the other root
+// settings, processors apply to the whole tree.
+func (logger *Logger) AddProcessor(fn func(record *Record)) {
+	root := logger.RootLogger()
+
+	root.mutex.Lock()
+	defer root.mutex.Unlock()
+
+	root.processors = append(slices.Clip(root.processors), fn)
+}
+
+// LogBatch dispatches records built by the caller, iterating the handlers once for the whole
+// batch. Handlers implementing [BatchHandler] receive all records in a single call. Records
+// below the minimum level are dropped and records without a time are stamped with the
+// current time. The caller of each record is left as given.
+func (logger *Logger) LogBatch(records []Record) error {
+	if logger.IsClosed() {
+		return ErrLoggerClosed
+	}
+
+	minLevel, hasMinLevel := logger.MinLevel()
+	now := logger.timestamp(time.Now())
+
+	batch := make([]Record, 0, len(records))
+	for _, record := range records {
+		if hasMinLevel && record.Level < minLevel {
+			continue
+		}
+
+		if record.Time.IsZero() {
+			record.Time = now
+		}
+
+		batch = append(batch, logger.prepareRecord(record))
+	}
+
+	if len(batch) == 0 {
+		return nil
 	}
 
 	errs := make([]error, 0)
 	for _, handler := range logger.Handlers() {
-		errs = append(errs, handler.HandleRecord(logger, record))
+		if batchHandler, ok := handler.(BatchHandler); ok {
+			if err := batchHandler.HandleRecords(logger, batch); err != nil {
+				logger.reportHandlerError(handler, err)
+				errs = append(errs, err)
+			}
+
+			continue
+		}
+
+		for _, record := range batch {
+			if err := handler.HandleRecord(logger, record); err != nil {
+				logger.reportHandlerError(handler, err)
+				errs = append(errs, err)
+			}
+		}
 	}
 
 	return errors.Join(errs...)
 }
 
+// LoggerStats summarizes the records logged directly to a logger, not including its children
+type LoggerStats struct {
+	CreatedAt time.Time
+	Counts    map[Level]uint64
+}
+
+// Stats returns the number of records logged at each level since the logger was created.
+// Levels without records are omitted.
+func (logger *Logger) Stats() LoggerStats {
+	stats := LoggerStats{CreatedAt: logger.createdAt, Counts: make(map[Level]uint64)}
+
+	for i := range logger.recordCounts {
+		if count := logger.recordCounts[i].Load(); count > 0 {
+			stats.Counts[LevelTrace+Level(i)] = count
+		}
+	}
+
+	return stats
+}
+
+func (logger *Logger) Trace(message string, args ...any) (err error) {
+	err = logger.Log(LevelTrace, message, args...)
+	if err != nil && logger.PanicOnError() {
+		panic(err)
+	}
+
+	return err
+}
+
 func (logger *Logger) Debug(message string, args ...any) (err error) {
 	err = logger.Log(LevelDebug, message, args...)
 	if err != nil && logger.PanicOnError() {
@@ -265,12 +1317,42 @@ func (logger *Logger) Error(message string, args ...any) (err error) {
 }
 
 func (logger *Logger) Fatal(message string, args ...any) {
+	logger.FatalCode(logger.fatalExitCode(), message, args...)
+}
+
+// FatalCode is like [Logger.Fatal] but exits with code instead of the default exit code
+func (logger *Logger) FatalCode(code int, message string, args ...any) {
 	err := logger.Log(LevelFatal, message, args...)
 	if err != nil && logger.PanicOnError() {
 		panic(err)
 	}
 
-	os.Exit(1)
+	logger.flushHandlers()
+	os.Exit(code)
+}
+
+// SetFatalExitCode sets the exit code used by [Logger.Fatal] and [Logger.FatalAttrs] for the
+// whole tree. The default is 1, setting 0 restores it.
+func (logger *Logger) SetFatalExitCode(code int) {
+	root := logger.RootLogger()
+
+	root.mutex.Lock()
+	defer root.mutex.Unlock()
+
+	root.exitCode = code
+}
+
+func (logger *Logger) fatalExitCode() int {
+	root := logger.RootLogger()
+
+	root.mutex.RLock()
+	defer root.mutex.RUnlock()
+
+	if root.exitCode == 0 {
+		return 1
+	}
+
+	return root.exitCode
 }
 
 func (logger *Logger) Panic(message string, args ...any) {
@@ -279,7 +1361,183 @@ func (logger *Logger) Panic(message string, args ...any) {
 		panic(err)
 	}
 
-	panic("an unrecoverable error has occurred")
+	logger.flushHandlers()
+	panic(message)
+}
+
+func (logger *Logger) TraceAttrs(message string, attrs ...Attribute) (err error) {
+	err = logger.LogAttrs(LevelTrace, message, attrs...)
+	if err != nil && logger.PanicOnError() {
+		panic(err)
+	}
+
+	return err
+}
+
+func (logger *Logger) DebugAttrs(message string, attrs ...Attribute) (err error) {
+	err = logger.LogAttrs(LevelDebug, message, attrs...)
+	if err != nil && logger.PanicOnError() {
+		panic(err)
+	}
+
+	return err
+}
+
+func (logger *Logger) InfoAttrs(message string, attrs ...Attribute) (err error) {
+	err = logger.LogAttrs(LevelInfo, message, attrs...)
+	if err != nil && logger.PanicOnError() {
+		panic(err)
+	}
+
+	return err
+}
+
+func (logger *Logger) WarnAttrs(message string, attrs ...Attribute) (err error) {
+	err = logger.LogAttrs(LevelWarn, message, attrs...)
+	if err != nil && logger.PanicOnError() {
+		panic(err)
+	}
+
+	return err
+}
+
+func (logger *Logger) ErrorAttrs(message string, attrs ...Attribute) (err error) {
+	err = logger.LogAttrs(LevelError, message, attrs...)
+	if err != nil && logger.PanicOnError() {
+		panic(err)
+	}
+
+	return err
+}
+
+// LogError logs err at [LevelError] with attributes describing it, followed by args, and
+// returns err so that it can be logged and returned in one statement. Nothing is logged if
+// err is nil.
+func (logger *Logger) LogError(err error, message string, args ...any) error {
+	if err == nil {
+		return nil
+	}
+
+	logErr := logger.LogAttrs(LevelError, message, append(errorAttrs(err), argsToAttrs(args)...)...)
+	if logErr != nil && logger.PanicOnError() {
+		panic(logErr)
+	}
+
+	return err
+}
+
+// Timed runs fn and logs message at level with the time it took in the "duration" attribute,
+// along with the error if fn fails. It returns the error of fn.
+func (logger *Logger) Timed(level Level, message string, fn func() error) error {
+	start := time.Now()
+	err := fn()
+
+	attrs := []Attribute{{Key: "duration", Value: time.Since(start)}}
+	if err != nil {
+		attrs = append(attrs, errorAttrs(err)...)
+	}
+
+	logErr := logger.LogAttrs(level, message, attrs...)
+	if logErr != nil && logger.PanicOnError() {
+		panic(logErr)
+	}
+
+	return err
+}
+
+// StartTimer starts timing a block and returns a function that logs message at [LevelInfo]
+// with the time elapsed since in the "duration" attribute:
+//
+//	stop := logger.StartTimer("handled request")
+//	defer stop()
+func (logger *Logger) StartTimer(message string) (stop func()) {
+	start := time.Now()
+
+	return func() {
+		err := logger.LogAttrs(LevelInfo, message, Attribute{Key: "duration", Value: time.Since(start)})
+		if err != nil && logger.PanicOnError() {
+			panic(err)
+		}
+	}
+}
+
+func (logger *Logger) FatalAttrs(message string, attrs ...Attribute) {
+	err := logger.LogAttrs(LevelFatal, message, attrs...)
+	if err != nil && logger.PanicOnError() {
+		panic(err)
+	}
+
+	logger.flushHandlers()
+	os.Exit(logger.fatalExitCode())
+}
+
+func (logger *Logger) PanicAttrs(message string, attrs ...Attribute) {
+	err := logger.LogAttrs(LevelPanic, message, attrs...)
+	if err != nil && logger.PanicOnError() {
+		panic(err)
+	}
+
+	logger.flushHandlers()
+	panic(message)
+}
+
+// Recover logs a panic in progress at [LevelPanic] with its value and stack, then panics
+// again with the same value. It must be deferred directly, ex. defer logger.Recover().
+func (logger *Logger) Recover() {
+	if r := recover(); r != nil {
+		logger.logPanic(r)
+		panic(r)
+	}
+}
+
+// RecoverAndContinue is like [Logger.Recover] but stops the panic after logging it
+func (logger *Logger) RecoverAndContinue() {
+	if r := recover(); r != nil {
+		logger.logPanic(r)
+	}
+}
+
+func (logger *Logger) logPanic(r any) {
+	logger.LogAttrs(LevelPanic, fmt.Sprintf("panic: %v", r),
+		Attribute{Key: "panic", Value: r},
+		Attribute{Key: "stack", Value: string(debug.Stack())},
+	)
+
+	logger.flushHandlers()
+}
+
+// flushHandlers flushes every handler implementing [Flusher] so that buffered records are
+// not lost when the process is about to exit
+func (logger *Logger) flushHandlers() error {
+	errs := make([]error, 0)
+	for _, handler := range logger.Handlers() {
+		if flusher, ok := handler.(Flusher); ok {
+			errs = append(errs, flusher.Flush())
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+var ErrBadAttrArgs = errors.New("bad attribute arguments")
+
+// checkArgs reports the first key without a value or value in a key position, which
+// argsToAttrs logs as !BADKEY
+func checkArgs(args []any) error {
+	for i := 0; i < len(args); i++ {
+		switch x := args[i].(type) {
+		case Attribute:
+		case string:
+			if i == len(args)-1 {
+				return fmt.Errorf("%w: key %q has no value", ErrBadAttrArgs, x)
+			}
+			i++
+		default:
+			return fmt.Errorf("%w: argument %d is a %T in a key position", ErrBadAttrArgs, i, x)
+		}
+	}
+
+	return nil
 }
 
 func argsToAttrs(args []any) (attr []Attribute) {
@@ -297,6 +1555,8 @@ func argsToAttrs(args []any) (attr []Attribute) {
 
 func nextAttrFromArgs(args []any) (attr Attribute, remaining []any) {
 	switch x := args[0].(type) {
+	case Attribute:
+		return x, args[1:]
 	case string:
 		if len(args) == 1 {
 			return Attribute{Key: "!BADKEY", Value: x}, nil
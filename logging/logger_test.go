@@ -0,0 +1,125 @@
+package logging
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestGetPackagePath(t *testing.T) {
+	tests := map[string]string{
+		"main":           "main",
+		"":               "",
+		"main.main":      "main",
+		"runtime.goexit": "runtime",
+		"github.com/link00000000/go-telemetry/logging.(*Logger).Log": "github.com/link00000000/go-telemetry/logging",
+		"github.com/link00000000/go-telemetry/logging":               "github.com/link00000000/go-telemetry/logging",
+		"example.com/pkg.Map[...]":                                   "example.com/pkg",
+		"example.com/pkg.Map[example.com/other.T].func1":             "example.com/pkg",
+		"_cgo_topofstack": "_cgo_topofstack",
+	}
+
+	for input, want := range tests {
+		if got := getPackagePath(input); got != want {
+			t.Errorf("getPackagePath(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestIsIgnoredPackage(t *testing.T) {
+	ignored := []string{"github.com/gin-gonic/gin"}
+
+	tests := map[string]bool{
+		"github.com/gin-gonic/gin":         true,
+		"github.com/gin-gonic/gin/render":  true,
+		"github.com/gin-gonic/ginkgo":      false,
+		"github.com/gin-gonic/gin-contrib": false,
+		"github.com/gin-gonic":             false,
+		"runtime":                          true,
+		loggingPackage:                     true,
+		"main":                             false,
+	}
+
+	for pkg, want := range tests {
+		if got := isIgnoredPackage(pkg, ignored); got != want {
+			t.Errorf("isIgnoredPackage(%q) = %v, want %v", pkg, got, want)
+		}
+	}
+}
+
+func TestSetCaptureCaller(t *testing.T) {
+	var got Record
+	logger := NewLogger()
+	logger.AddHandler(NewFuncHandler(LevelTrace, func(logger *Logger, record Record) error {
+		got = record
+		return nil
+	}))
+
+	logger.Info("message")
+	if got.Caller == nil {
+		t.Error("no caller with capture enabled")
+	}
+
+	logger.SetCaptureCaller(false)
+	logger.Info("message")
+	if got.Caller != nil {
+		t.Errorf("got caller %v with capture disabled", got.Caller)
+	}
+}
+
+func TestAddHandlerDuplicates(t *testing.T) {
+	var a, b bytes.Buffer
+	noop := func(logger *Logger, record Record) error { return nil }
+
+	tests := []struct {
+		name     string
+		first    Handler
+		second   Handler
+		attached int
+	}{
+		{"equal values", NewPrettyHandler(&a, LevelInfo), NewPrettyHandler(&a, LevelInfo), 1},
+		{"different writers", NewPrettyHandler(&a, LevelInfo), NewPrettyHandler(&b, LevelInfo), 2},
+		{"different settings", NewPrettyHandler(&a, LevelInfo), NewCompactHandler(&a, LevelInfo), 2},
+		{"distinct pointers", NewFuncHandler(LevelInfo, noop), NewFuncHandler(LevelInfo, noop), 2},
+		{"same pointer", NewCountingHandler(NewDiscardHandler()), nil, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.second == nil {
+				tt.second = tt.first
+			}
+
+			logger := NewLogger()
+			logger.AddHandler(tt.first)
+			logger.AddHandler(tt.second)
+
+			if got := len(logger.handlers); got != tt.attached {
+				t.Fatalf("got %d handlers, want %d", got, tt.attached)
+			}
+
+			// Removes the second handler, or the attached handler that it duplicates
+			if !logger.RemoveHandler(tt.second) {
+				t.Fatal("second handler not found")
+			}
+
+			if got := len(logger.handlers); got != tt.attached-1 {
+				t.Errorf("got %d handlers after removing, want %d", got, tt.attached-1)
+			}
+		})
+	}
+}
+
+func BenchmarkCaptureCaller(b *testing.B) {
+	for name, capture := range map[string]bool{"enabled": true, "disabled": false} {
+		b.Run(name, func(b *testing.B) {
+			logger := NewLogger()
+			logger.AddHandler(NewDiscardHandler())
+			logger.SetCaptureCaller(capture)
+
+			b.ReportAllocs()
+			for range b.N {
+				logger.Info("message")
+			}
+		})
+	}
+}
@@ -3,17 +3,18 @@ package logging
 import (
 	"fmt"
 	"io"
-	"os"
 	"path/filepath"
 	"runtime"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/link00000000/go-telemetry/logging/ansi"
 	"golang.org/x/term"
 )
 
-const globalPadding = "                     "
-
 var projectRoot string = "/"
 
 func init() {
@@ -25,23 +26,152 @@ func init() {
 	projectRoot = filepath.Dir(filepath.Dir(filepath.Dir(thisFile)))
 }
 
+// Builders that grew larger than this are not returned to the pool to avoid holding on to
+// the memory of an occasional large record
+const maxPooledPrettyBufferSize = 64 * 1024
+
+var prettyBuilderPool = sync.Pool{
+	New: func() any {
+		return &ansi.AnsiStringBuilder{}
+	},
+}
+
 type PrettyHandler struct {
 	writer io.Writer
 	level  Level
+
+	callerBasePath string
+	callerStyle    CallerStyle
+	lineColoring   bool
+	compact        bool
+	color          prettyColor
+	callerWidth    int
 }
 
+type prettyColor int
+
+const (
+	prettyColor_Auto prettyColor = iota
+	prettyColor_Always
+	prettyColor_Never
+)
+
 func NewPrettyHandler(writer io.Writer, level Level) PrettyHandler {
-	return PrettyHandler{writer: writer, level: level}
+	return PrettyHandler{writer: writer, level: level, callerBasePath: projectRoot}
+}
+
+// NewCompactHandler creates a pretty handler that writes each record on a single line, with
+// the attributes after the message as key=value pairs instead of a tree
+func NewCompactHandler(writer io.Writer, level Level) PrettyHandler {
+	handler := NewPrettyHandler(writer, level)
+	handler.compact = true
+	return handler
+}
+
+// WithCallerBasePath returns a copy of the handler that shows caller paths relative to
+// path instead of the detected project root
+func (handler PrettyHandler) WithCallerBasePath(path string) PrettyHandler {
+	handler.callerBasePath = path
+	return handler
+}
+
+// WithCallerStyle returns a copy of the handler that renders caller paths in style. With
+// [CallerStyle_ModuleRel], paths are relative to the caller base path.
+func (handler PrettyHandler) WithCallerStyle(style CallerStyle) PrettyHandler {
+	handler.callerStyle = style
+	return handler
+}
+
+// WithCallerWidth returns a copy of the handler that pads the caller column to width
+// characters so that messages start at the same column. Longer callers are shortened from
+// the start of the path with an ellipsis, keeping the line number. A width of 0 disables
+// padding, which is the default.
+func (handler PrettyHandler) WithCallerWidth(width int) PrettyHandler {
+	handler.callerWidth = width
+	return handler
+}
+
+// fitCaller pads or shortens caller to the caller width, counted in visible characters
+func (handler PrettyHandler) fitCaller(caller string) string {
+	if handler.callerWidth <= 0 {
+		return caller
+	}
+
+	runes := []rune(caller)
+	if len(runes) <= handler.callerWidth {
+		return caller + strings.Repeat(" ", handler.callerWidth-len(runes))
+	}
+
+	// Keep the brackets around the shortened path
+	keep := handler.callerWidth - 3
+	if keep < 1 {
+		return string(runes[:handler.callerWidth])
+	}
+
+	return "<…" + string(runes[len(runes)-keep-1:len(runes)-1]) + ">"
+}
+
+func (handler PrettyHandler) callerPath(file string) string {
+	return formatCallerPath(file, handler.callerStyle, handler.callerBasePath)
+}
+
+// WithLineColoring returns a copy of the handler that tints the whole record, including the
+// message and attributes, in the color of its level instead of only the level badge
+func (handler PrettyHandler) WithLineColoring(enabled bool) PrettyHandler {
+	handler.lineColoring = enabled
+	return handler
+}
+
+// WithColor returns a copy of the handler that always or never writes colors, instead of
+// only when writing to a terminal
+func (handler PrettyHandler) WithColor(enabled bool) PrettyHandler {
+	if enabled {
+		handler.color = prettyColor_Always
+	} else {
+		handler.color = prettyColor_Never
+	}
+
+	return handler
 }
 
 func (handler PrettyHandler) useColor() bool {
-	file, ok := handler.writer.(*os.File)
+	switch handler.color {
+	case prettyColor_Always:
+		return true
+	case prettyColor_Never:
+		return false
+	}
+
+	fd, ok := writerFd(handler.writer)
 	if !ok {
 		return false
 	}
 
-	isTerm := term.IsTerminal(int(file.Fd()))
-	return isTerm
+	return term.IsTerminal(int(fd)) && enableVirtualTerminal(fd)
+}
+
+// writerFd returns the file descriptor of writer, looking through writers that expose the
+// writer they wrap with an Unwrap method
+func writerFd(writer io.Writer) (uintptr, bool) {
+	for writer != nil {
+		if file, ok := writer.(interface{ Fd() uintptr }); ok {
+			return file.Fd(), true
+		}
+
+		wrapper, ok := writer.(interface{ Unwrap() io.Writer })
+		if !ok {
+			break
+		}
+
+		writer = wrapper.Unwrap()
+	}
+
+	return 0, false
+}
+
+// Implements [logging.LeveledHandler]
+func (handler PrettyHandler) Level() Level {
+	return handler.level
 }
 
 // Implements [logging.Handler]
@@ -59,56 +189,83 @@ func (handler PrettyHandler) HandleRecord(logger *Logger, record Record) error {
 		return nil
 	}
 
-	var str ansi.AnsiStringBuilder
+	str := prettyBuilderPool.Get().(*ansi.AnsiStringBuilder)
+	defer func() {
+		if str.Cap() <= maxPooledPrettyBufferSize {
+			str.Reset()
+			prettyBuilderPool.Put(str)
+		}
+	}()
+
+	handler.appendRecord(str, logger, record)
+	return writeRecord(handler.writer, str.Bytes())
+}
+
+// appendRecord renders record into str
+func (handler PrettyHandler) appendRecord(str *ansi.AnsiStringBuilder, logger *Logger, record Record) {
 	if handler.useColor() {
 		str.SetEscapeMode(ansi.EscapeMode_Enable)
 	} else {
 		str.SetEscapeMode(ansi.EscapeMode_Disable)
 	}
 
-	str.Write(record.Time.Format("2006/01/02 15:04:05"), " ")
+	tint, tinted := levelLineColor(record.Level)
+	tinted = tinted && handler.lineColoring
+	if tinted {
+		str.WriteEscapeCode(tint)
+	}
 
-	switch record.Level {
-	case LevelDebug:
-		str.Write(ansi.FgMagenta, "DBG", ansi.Reset)
-	case LevelInfo:
-		str.Write(ansi.FgBlue, "INF", ansi.Reset)
-	case LevelWarn:
-		str.Write(ansi.FgYellow, "WRN", ansi.Reset)
-	case LevelError:
-		str.Write(ansi.FgRed, "ERR", ansi.Reset)
-	case LevelFatal:
-		str.Write(ansi.FgBlack, ansi.BgRed, "FTL", ansi.Reset)
-	case LevelPanic:
-		str.Write(ansi.FgBlack, ansi.BgRed, "!!!", ansi.Reset)
+	timestamp := record.Time.UTC().Format("2006/01/02 15:04:05")
+	str.Write(timestamp, " ")
+
+	levelWidth := writeLevelLabel(str, record.Level)
+
+	// The rest of the record is written without its own colors so that the tint is not reset
+	escapeMode := str.EscapeMode()
+	if tinted {
+		str.WriteEscapeCode(tint)
+		str.SetEscapeMode(ansi.EscapeMode_Disable)
 	}
 
+	// Align the keys of the attribute tree, after their "├─ " branches, with the column
+	// following the time and level
+	prefixWidth := utf8.RuneCountInString(timestamp) + 1 + levelWidth + 1
+	padding := strings.Repeat(" ", prefixWidth-utf8.RuneCountInString("├─ "))
+
 	str.WriteString(" ")
 
-	var callerRelativePath *string
+	caller := "<UNKNOWN CALLER>"
 	if record.Caller != nil {
-		if relativePath, err := filepath.Rel(projectRoot, record.Caller.File); err == nil {
-			callerRelativePath = &relativePath
-		}
+		caller = fmt.Sprintf("<%s:%d>", handler.callerPath(record.Caller.File), record.Caller.Line)
 	}
 
-	if callerRelativePath != nil {
-		str.Write(ansi.FgBrightBlack, fmt.Sprintf("<%s:%d> ", *callerRelativePath, record.Caller.Line), ansi.Reset)
-	} else {
-		str.Write(ansi.FgBrightBlack, "<UNKNOWN CALLER> ", ansi.Reset)
-	}
+	caller = handler.fitCaller(caller) + " "
 
-	str.WriteString(record.Message)
+	str.Write(ansi.FgBrightBlack, caller, ansi.Reset)
 
-	str.WriteString("\n")
+	// Continuation lines of multi-line messages are aligned under the start of the message
+	messageIndent := strings.Repeat(" ", prefixWidth+utf8.RuneCountInString(caller))
+	message := expandMessage(record)
+	str.WriteString(strings.ReplaceAll(message, "\n", "\n"+messageIndent))
+
+	if handler.compact {
+		printAttrsInline(str, resolveAttrs(record.Attributes), "")
+		str.WriteString("\n")
+	} else {
+		str.WriteString("\n")
+		printAttrsRec(str, resolveAttrs(record.Attributes), padding)
+	}
 
-	printAttrsRec(&str, record.Attributes, globalPadding)
+	if tinted {
+		str.SetEscapeMode(escapeMode)
+		str.WriteEscapeCode(ansi.Reset)
+	}
 
 	/*
 		dataJson, err := json.Marshal(logger.data)
 		if err != nil && (strings.Contains(err.Error(), "unsupported type") || strings.Contains(err.Error(), "unsupported value")) {
 			// Fallback to non-recursive printing
-			printData(&str, logger.data, globalPadding)
+			printData(str, logger.data, padding)
 		} else if err != nil {
 			return err
 		} else {
@@ -118,12 +275,101 @@ func (handler PrettyHandler) HandleRecord(logger *Logger, record Record) error {
 				return err
 			}
 
-			printDataRec(&str, dataMap, globalPadding)
+			printDataRec(str, dataMap, padding)
 		}
 	*/
+}
+
+// expandMessage replaces the {key} placeholders in the message of record with the values of
+// the matching attributes, addressing groups with dots like [Record.AttrValue].
+// Placeholders without a matching attribute are left as they are.
+func expandMessage(record Record) string {
+	message := record.Message
+	if !strings.Contains(message, "{") {
+		return message
+	}
+
+	var expanded strings.Builder
+	for {
+		start := strings.IndexByte(message, '{')
+		if start == -1 {
+			break
+		}
+
+		end := strings.IndexByte(message[start:], '}')
+		if end == -1 {
+			break
+		}
+
+		end += start
+		key := message[start+1 : end]
+
+		value, ok := record.AttrValue(key)
+		if !ok || key == "" {
+			// Continue after the brace, the placeholder may start later
+			expanded.WriteString(message[:start+1])
+			message = message[start+1:]
+			continue
+		}
+
+		expanded.WriteString(message[:start])
+		switch v := resolveValue(value).(type) {
+		case error:
+			expanded.WriteString(v.Error())
+		default:
+			fmt.Fprintf(&expanded, "%v", v)
+		}
+
+		message = message[end+1:]
+	}
+
+	expanded.WriteString(message)
+	return expanded.String()
+}
+
+// writeLevelLabel writes the badge for level and returns its visible width
+func writeLevelLabel(str *ansi.AnsiStringBuilder, level Level) int {
+	switch level {
+	case LevelTrace:
+		str.Write(ansi.Dim, "TRC", ansi.Reset)
+	case LevelDebug:
+		str.Write(ansi.FgMagenta, "DBG", ansi.Reset)
+	case LevelInfo:
+		str.Write(ansi.FgBlue, "INF", ansi.Reset)
+	case LevelWarn:
+		str.Write(ansi.FgYellow, "WRN", ansi.Reset)
+	case LevelError:
+		str.Write(ansi.FgRed, "ERR", ansi.Reset)
+	case LevelFatal:
+		str.WriteCombined(ansi.FgBlack, ansi.BgRed)
+		str.Write("FTL", ansi.Reset)
+	case LevelPanic:
+		str.WriteCombined(ansi.FgBlack, ansi.BgRed)
+		str.Write("!!!", ansi.Reset)
+	default:
+		return 0
+	}
+
+	return 3
+}
 
-	_, err := fmt.Fprintf(handler.writer, str.String())
-	return err
+// levelLineColor returns the color used to tint records of level with line coloring. Info
+// records are not tinted.
+func levelLineColor(level Level) (ansi.EscapeCode, bool) {
+	switch {
+	case level == LevelInfo:
+		return 0, false
+	case level <= LevelTrace:
+		return ansi.Dim, true
+	case level == LevelDebug:
+		return ansi.FgBrightBlack, true
+	case level == LevelWarn:
+		return ansi.FgYellow, true
+	case level >= LevelError:
+		return ansi.FgRed, true
+	default:
+		return 0, false
+	}
 }
 
 func printData(str *ansi.AnsiStringBuilder, data map[string]any, padding string) {
@@ -194,9 +440,63 @@ func printAttrsRec(str *ansi.AnsiStringBuilder, attrs []Attribute, padding strin
 				printAttrsRec(str, v, padding+"    ")
 			}
 		case error:
-			str.Write(ansi.FgBrightBlack, attr.Key, ansi.Reset, ": ", fmt.Sprintf("%#v \"%s\"", v, v.Error()), "\n")
+			str.Write(ansi.FgBrightBlack, attr.Key, ansi.Reset, ": ", ansi.FgRed, fmt.Sprintf("%#v \"%s\"", v, v.Error()), ansi.Reset, "\n")
+
+			details := errorDetails(v)
+			if !isLast {
+				printAttrsRec(str, details, padding+"│   ")
+			} else {
+				printAttrsRec(str, details, padding+"    ")
+			}
+		case time.Duration:
+			str.Write(ansi.FgBrightBlack, attr.Key, ansi.Reset, ": ", ansi.FgCyan, v.String(), ansi.Reset, "\n")
+		case Bytes:
+			str.Write(ansi.FgBrightBlack, attr.Key, ansi.Reset, ": ", ansi.FgYellow, v.String(), ansi.Reset, "\n")
+		case bool:
+			str.Write(ansi.FgBrightBlack, attr.Key, ansi.Reset, ": ", ansi.FgMagenta, fmt.Sprintf("%#v", v), ansi.Reset, "\n")
+		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, uintptr, float32, float64:
+			str.Write(ansi.FgBrightBlack, attr.Key, ansi.Reset, ": ", ansi.FgYellow, fmt.Sprintf("%#v", v), ansi.Reset, "\n")
 		default:
 			str.Write(ansi.FgBrightBlack, attr.Key, ansi.Reset, ": ", fmt.Sprintf("%#v", v), "\n")
 		}
 	}
 }
+
+// printAttrsInline writes attrs as space separated key=value pairs, flattening groups into
+// dotted keys
+func printAttrsInline(str *ansi.AnsiStringBuilder, attrs []Attribute, prefix string) {
+	for _, attr := range attrs {
+		key := prefix + attr.Key
+
+		if group, ok := attr.Value.([]Attribute); ok {
+			printAttrsInline(str, group, key+".")
+			continue
+		}
+
+		str.Write(" ", ansi.FgBrightBlack, key, "=", ansi.Reset)
+
+		switch v := attr.Value.(type) {
+		case error:
+			str.Write(ansi.FgRed, inlineValue(v.Error()), ansi.Reset)
+		case time.Duration:
+			str.Write(ansi.FgCyan, v.String(), ansi.Reset)
+		case Bytes:
+			str.Write(ansi.FgYellow, inlineValue(v.String()), ansi.Reset)
+		case bool:
+			str.Write(ansi.FgMagenta, fmt.Sprintf("%v", v), ansi.Reset)
+		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, uintptr, float32, float64:
+			str.Write(ansi.FgYellow, fmt.Sprintf("%v", v), ansi.Reset)
+		default:
+			str.WriteString(inlineValue(fmt.Sprintf("%v", v)))
+		}
+	}
+}
+
+// inlineValue quotes s if it would be ambiguous in a key=value list
+func inlineValue(s string) string {
+	if s == "" || strings.ContainsAny(s, " =\"\t\r\n") {
+		return strconv.Quote(s)
+	}
+
+	return s
+}
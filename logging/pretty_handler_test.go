@@ -0,0 +1,263 @@
+package logging
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/link00000000/go-telemetry/logging/ansi"
+)
+
+func TestPrettyHandlerPadding(t *testing.T) {
+	record := Record{
+		Time:    time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Level:   LevelInfo,
+		Message: "hello",
+		Caller:  &runtime.Frame{File: "/src/main.go", Line: 12},
+		Attributes: []Attribute{
+			{Key: "group", Value: []Attribute{{Key: "a", Value: 1}}},
+			{Key: "b", Value: "c"},
+		},
+	}
+
+	var buf bytes.Buffer
+	handler := NewPrettyHandler(&buf, LevelTrace).WithCallerBasePath("/src").WithColor(false)
+	if err := handler.HandleRecord(NewLogger(), record); err != nil {
+		t.Fatal(err)
+	}
+
+	// The tree starts in the same column as before the padding was derived from the prefix
+	want := "2024/01/02 03:04:05 INF <main.go:12> hello\n" +
+		"                     ├─ group\n" +
+		"                     │   └─ a: 1\n" +
+		"                     └─ b: \"c\"\n"
+
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestPrintAttrsRecColors(t *testing.T) {
+	tests := []struct {
+		attr Attribute
+		want string
+	}{
+		{Attribute{Key: "d", Value: 1500 * time.Millisecond}, "\033[90md\033[0m: \033[36m1.5s\033[0m\n"},
+		{Attribute{Key: "n", Value: 42}, "\033[90mn\033[0m: \033[33m42\033[0m\n"},
+		{Attribute{Key: "f", Value: 1.5}, "\033[90mf\033[0m: \033[33m1.5\033[0m\n"},
+		{Attribute{Key: "b", Value: true}, "\033[90mb\033[0m: \033[35mtrue\033[0m\n"},
+	}
+
+	for _, test := range tests {
+		for _, mode := range []ansi.EscapeMode{ansi.EscapeMode_Enable, ansi.EscapeMode_Disable} {
+			var str ansi.AnsiStringBuilder
+			str.SetEscapeMode(mode)
+			printAttrsRec(&str, []Attribute{test.attr}, "")
+
+			want := "└─ " + test.want
+			if mode == ansi.EscapeMode_Disable {
+				want = "└─ " + test.attr.Key + ": " + fmt.Sprintf("%v", test.attr.Value) + "\n"
+			}
+
+			if got := str.String(); got != want {
+				t.Errorf("%s with escape mode %v: got %q, want %q", test.attr.Key, mode, got, want)
+			}
+		}
+	}
+}
+
+func TestPrettyHandlerCallerOutsideBasePath(t *testing.T) {
+	tests := map[string]string{
+		"/src/project/pkg/file.go": "<pkg/file.go:3>",
+		"/src/other/pkg/file.go":   "<file.go:3>",
+		"/src/project-old/file.go": "<file.go:3>",
+		"relative/path/to/file.go": "<file.go:3>",
+	}
+
+	for file, want := range tests {
+		var buf bytes.Buffer
+		handler := NewPrettyHandler(&buf, LevelTrace).WithCallerBasePath("/src/project").WithColor(false)
+
+		record := Record{Level: LevelInfo, Message: "message", Caller: &runtime.Frame{File: file, Line: 3}}
+		if err := handler.HandleRecord(NewLogger(), record); err != nil {
+			t.Fatal(err)
+		}
+
+		if got := buf.String(); !strings.Contains(got, " "+want+" message") {
+			t.Errorf("caller %s: got %q, want caller %s", file, got, want)
+		}
+	}
+}
+
+// fdWriter is a writer exposing a file descriptor, like *os.File
+type fdWriter struct {
+	bytes.Buffer
+	fd uintptr
+}
+
+func (w *fdWriter) Fd() uintptr {
+	return w.fd
+}
+
+func TestWriterFd(t *testing.T) {
+	direct := &fdWriter{fd: 42}
+
+	tests := []struct {
+		name   string
+		writer io.Writer
+		fd     uintptr
+		ok     bool
+	}{
+		{"direct", direct, 42, true},
+		{"file", os.Stderr, os.Stderr.Fd(), true},
+		{"wrapped", NewSyncWriter(direct), 42, true},
+		{"wrapped twice", NewSyncWriter(NewSyncWriter(direct)), 42, true},
+		{"buffer", &bytes.Buffer{}, 0, false},
+		{"wrapped buffer", NewSyncWriter(&bytes.Buffer{}), 0, false},
+		{"nil", nil, 0, false},
+	}
+
+	for _, test := range tests {
+		if fd, ok := writerFd(test.writer); fd != test.fd || ok != test.ok {
+			t.Errorf("%s: got (%d, %v), want (%d, %v)", test.name, fd, ok, test.fd, test.ok)
+		}
+	}
+}
+
+func TestPrettyHandlerWithColor(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler func(writer io.Writer) PrettyHandler
+		want    bool
+	}{
+		{"detected", func(writer io.Writer) PrettyHandler { return NewPrettyHandler(writer, LevelTrace) }, false},
+		{"always", func(writer io.Writer) PrettyHandler { return NewPrettyHandler(writer, LevelTrace).WithColor(true) }, true},
+		{"never", func(writer io.Writer) PrettyHandler { return NewPrettyHandler(writer, LevelTrace).WithColor(false) }, false},
+	}
+
+	for _, test := range tests {
+		for _, wrapped := range []bool{false, true} {
+			var buf bytes.Buffer
+
+			// Neither writer is a terminal, so colors are only written when forced
+			var writer io.Writer = &buf
+			if wrapped {
+				writer = NewSyncWriter(&buf)
+			}
+
+			if err := test.handler(writer).HandleRecord(NewLogger(), Record{Level: LevelInfo, Message: "message"}); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := strings.Contains(buf.String(), "\033["); got != test.want {
+				t.Errorf("%s, wrapped %v: got colors %v, want %v in %q", test.name, wrapped, got, test.want, buf.String())
+			}
+		}
+	}
+}
+
+func TestPrettyHandlerDetectsWrappedTerminal(t *testing.T) {
+	tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err != nil {
+		t.Skip("no terminal:", err)
+	}
+	defer tty.Close()
+
+	for _, writer := range []io.Writer{tty, NewSyncWriter(tty)} {
+		if !NewPrettyHandler(writer, LevelTrace).useColor() {
+			t.Errorf("colors are disabled for terminal writer %T", writer)
+		}
+
+		if NewPrettyHandler(writer, LevelTrace).WithColor(false).useColor() {
+			t.Errorf("colors are enabled for terminal writer %T with WithColor(false)", writer)
+		}
+	}
+}
+
+func TestPrettyHandlerMultiLineMessage(t *testing.T) {
+	tests := []struct {
+		name        string
+		message     string
+		callerWidth int
+		want        string
+	}{
+		{
+			"two lines", "SELECT *\nFROM t", 0,
+			"2024/01/02 03:04:05 INF <main.go:12> SELECT *\n" +
+				"                                     FROM t\n",
+		},
+		{
+			"caller width", "SELECT *\nFROM t", 16,
+			"2024/01/02 03:04:05 INF <main.go:12>     SELECT *\n" +
+				"                                         FROM t\n",
+		},
+		{
+			"single line", "SELECT * FROM t", 0,
+			"2024/01/02 03:04:05 INF <main.go:12> SELECT * FROM t\n",
+		},
+	}
+
+	for _, test := range tests {
+		record := Record{
+			Time:    time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+			Level:   LevelInfo,
+			Message: test.message,
+			Caller:  &runtime.Frame{File: "/src/main.go", Line: 12},
+		}
+
+		var buf bytes.Buffer
+		handler := NewPrettyHandler(&buf, LevelTrace).WithCallerBasePath("/src").WithCallerWidth(test.callerWidth).WithColor(false)
+		if err := handler.HandleRecord(NewLogger(), record); err != nil {
+			t.Fatal(err)
+		}
+
+		if got := buf.String(); got != test.want {
+			t.Errorf("%s: got %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func BenchmarkPrettyHandler(b *testing.B) {
+	record := Record{
+		Time:    time.Now(),
+		Level:   LevelInfo,
+		Message: "request done",
+		Caller:  &runtime.Frame{File: "/src/main.go", Line: 12, Function: "main.main"},
+		Attributes: []Attribute{
+			String("method", "GET"),
+			Int("status", 200),
+			Duration("elapsed", 15*time.Millisecond),
+		},
+	}
+
+	handlers := map[string]PrettyHandler{
+		"tree":    NewPrettyHandler(io.Discard, LevelTrace).WithColor(true),
+		"compact": NewCompactHandler(io.Discard, LevelTrace).WithColor(true),
+	}
+
+	logger := NewLogger()
+	for name, handler := range handlers {
+		b.Run(name+"/pooled", func(b *testing.B) {
+			b.ReportAllocs()
+			for range b.N {
+				handler.HandleRecord(logger, record)
+			}
+		})
+
+		// Renders each record into a new builder and copies it out as before the builders
+		// were pooled, for comparing the allocations
+		b.Run(name+"/fresh", func(b *testing.B) {
+			b.ReportAllocs()
+			for range b.N {
+				var str ansi.AnsiStringBuilder
+				handler.appendRecord(&str, logger, record)
+				io.Discard.Write([]byte(str.String()))
+			}
+		})
+	}
+}
@@ -0,0 +1,18 @@
+package logging
+
+import (
+	"os"
+	"sync"
+)
+
+// processHostname returns the hostname of the machine, which is only looked up once
+var processHostname = sync.OnceValue(func() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return ""
+	}
+
+	return hostname
+})
+
+var processId = os.Getpid()
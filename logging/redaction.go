@@ -0,0 +1,107 @@
+package logging
+
+import (
+	"slices"
+	"strings"
+)
+
+// RedactedValue replaces the value of redacted attributes
+const RedactedValue = "[REDACTED]"
+
+type redactor func(key string, value any) (any, bool)
+
+// Redact replaces the value of every attribute whose key matches one of keys with
+// [RedactedValue] before the record reaches the handlers. Keys are matched case-insensitively
+// against both the attribute key and its dotted path within groups, such as "user.password".
+func (logger *Logger) Redact(keys ...string) {
+	root := logger.RootLogger()
+
+	root.mutex.Lock()
+	defer root.mutex.Unlock()
+
+	// Replace rather than modify the set, records being logged may still be reading it
+	redactedKeys := make(map[string]struct{}, len(root.redactedKeys)+len(keys))
+	for key := range root.redactedKeys {
+		redactedKeys[key] = struct{}{}
+	}
+
+	for _, key := range keys {
+		redactedKeys[strings.ToLower(key)] = struct{}{}
+	}
+
+	root.redactedKeys = redactedKeys
+}
+
+// RedactFunc registers fn to inspect every attribute before the record reaches the handlers.
+// fn receives the dotted path of the attribute and its value, and returns the replacement
+// value and true to redact it. Lazy values are inspected once they are evaluated.
+func (logger *Logger) RedactFunc(fn func(key string, value any) (any, bool)) {
+	root := logger.RootLogger()
+
+	root.mutex.Lock()
+	defer root.mutex.Unlock()
+
+	root.redactors = append(slices.Clip(root.redactors), fn)
+}
+
+// redact applies the redaction rules of the root logger to attrs
+func (logger *Logger) redact(attrs []Attribute) []Attribute {
+	root := logger.RootLogger()
+
+	root.mutex.RLock()
+	keys, redactors := root.redactedKeys, root.redactors
+	root.mutex.RUnlock()
+
+	if len(keys) == 0 && len(redactors) == 0 {
+		return attrs
+	}
+
+	return redactAttrs(attrs, "", keys, redactors)
+}
+
+func redactAttrs(attrs []Attribute, prefix string, keys map[string]struct{}, redactors []redactor) []Attribute {
+	redacted := make([]Attribute, len(attrs))
+
+	for i, attr := range attrs {
+		path := prefix + attr.Key
+		redacted[i] = Attribute{Key: attr.Key, Value: redactValue(attr.Key, path, attr.Value, keys, redactors)}
+	}
+
+	return redacted
+}
+
+func redactValue(key string, path string, value any, keys map[string]struct{}, redactors []redactor) any {
+	if _, ok := keys[strings.ToLower(key)]; ok {
+		return RedactedValue
+	}
+
+	if _, ok := keys[strings.ToLower(path)]; ok {
+		return RedactedValue
+	}
+
+	switch v := value.(type) {
+	case []Attribute:
+		return redactAttrs(v, path+".", keys, redactors)
+	case LazyValue:
+		if len(redactors) == 0 {
+			return v
+		}
+
+		return LazyValue(func() any {
+			return redactValue(key, path, v(), keys, redactors)
+		})
+	case LogValuer:
+		// The logged representation may be a group whose keys need redacting
+		return LazyValue(func() any {
+			return redactValue(key, path, v.LogValue(), keys, redactors)
+		})
+	}
+
+	for _, fn := range redactors {
+		if replacement, ok := fn(path, value); ok {
+			return replacement
+		}
+	}
+
+	return value
+}
@@ -0,0 +1,179 @@
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"runtime"
+	"sync"
+	"time"
+)
+
+type ringBufferEntry struct {
+	logger *Logger
+	record Record
+}
+
+// RingBufferHandler keeps the most recent records in memory so they can be dumped after a
+// crash
+type RingBufferHandler struct {
+	mutex sync.Mutex
+
+	level     Level
+	formatter Formatter
+
+	entries []ringBufferEntry
+	next    int
+	full    bool
+}
+
+// NewRingBufferHandler creates a handler retaining the last capacity records. Records are
+// rendered with formatter when dumped, or with [PrettyFormatter] if formatter is nil.
+func NewRingBufferHandler(capacity int, level Level, formatter Formatter) *RingBufferHandler {
+	if capacity <= 0 {
+		panic("ring buffer capacity must be positive")
+	}
+
+	if formatter == nil {
+		formatter = PrettyFormatter
+	}
+
+	return &RingBufferHandler{
+		level:     level,
+		formatter: formatter,
+		entries:   make([]ringBufferEntry, capacity),
+	}
+}
+
+// snapshot returns the buffered entries from oldest to newest
+func (handler *RingBufferHandler) snapshot() []ringBufferEntry {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	if !handler.full {
+		return append([]ringBufferEntry(nil), handler.entries[:handler.next]...)
+	}
+
+	entries := make([]ringBufferEntry, 0, len(handler.entries))
+	entries = append(entries, handler.entries[handler.next:]...)
+	entries = append(entries, handler.entries[:handler.next]...)
+
+	return entries
+}
+
+// Snapshot returns a copy of the buffered records from oldest to newest
+func (handler *RingBufferHandler) Snapshot() []Record {
+	entries := handler.snapshot()
+
+	records := make([]Record, len(entries))
+	for i, entry := range entries {
+		records[i] = entry.record
+	}
+
+	return records
+}
+
+// Dump renders the buffered records to writer from oldest to newest
+func (handler *RingBufferHandler) Dump(writer io.Writer) error {
+	formatter := handler.formatter(writer)
+
+	errs := make([]error, 0)
+	for _, entry := range handler.snapshot() {
+		errs = append(errs, formatter.HandleRecord(entry.logger, entry.record))
+	}
+
+	return errors.Join(errs...)
+}
+
+// countingWriter counts the bytes written through it for [io.WriterTo]
+type countingWriter struct {
+	writer io.Writer
+	n      int64
+}
+
+// Implements [io.Writer]
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.writer.Write(p)
+	w.n += int64(n)
+	return n, err
+}
+
+// WriteTo renders the buffered records to writer as JSON from oldest to newest, see
+// [RingBufferHandler.WriteToFormat] for other formats. Implements [io.WriterTo].
+func (handler *RingBufferHandler) WriteTo(writer io.Writer) (int64, error) {
+	return handler.WriteToFormat(writer, JsonFormatter)
+}
+
+// WriteToFormat renders the buffered records to writer with formatter from oldest to newest
+// and returns the number of bytes written
+func (handler *RingBufferHandler) WriteToFormat(writer io.Writer, formatter Formatter) (int64, error) {
+	counter := &countingWriter{writer: writer}
+	h := formatter(counter)
+
+	for _, entry := range handler.snapshot() {
+		if err := h.HandleRecord(entry.logger, entry.record); err != nil {
+			return counter.n, err
+		}
+	}
+
+	return counter.n, nil
+}
+
+// ServeHTTP writes the buffered records as JSON, or in the pretty format when the format
+// query parameter is "pretty"
+func (handler *RingBufferHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch format := r.URL.Query().Get("format"); format {
+	case "", "json":
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		handler.WriteToFormat(w, JsonFormatter)
+	case "pretty":
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		handler.WriteToFormat(w, PrettyFormatter)
+	default:
+		http.Error(w, fmt.Sprintf("unknown format %q", format), http.StatusBadRequest)
+	}
+}
+
+// DumpOnPanic dumps the buffered records to writer and re-panics if the goroutine is
+// panicking. It must be called directly by defer.
+//
+//	defer ringBuffer.DumpOnPanic(os.Stderr)
+func (handler *RingBufferHandler) DumpOnPanic(writer io.Writer) {
+	if r := recover(); r != nil {
+		handler.Dump(writer)
+		panic(r)
+	}
+}
+
+// Implements [logging.LeveledHandler]
+func (handler *RingBufferHandler) Level() Level {
+	return handler.level
+}
+
+// Implements [logging.Handler]
+func (handler *RingBufferHandler) OnLoggerCreated(logger *Logger, timestamp time.Time, caller *runtime.Frame) {
+}
+
+// Implements [logging.Handler]
+func (handler *RingBufferHandler) OnLoggerClosed(logger *Logger, timestamp time.Time, caller *runtime.Frame) error {
+	return nil
+}
+
+// Implements [logging.Handler]
+func (handler *RingBufferHandler) HandleRecord(logger *Logger, record Record) error {
+	if record.Level < handler.level {
+		return nil
+	}
+
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	handler.entries[handler.next] = ringBufferEntry{logger: logger, record: record}
+	handler.next = (handler.next + 1) % len(handler.entries)
+	if handler.next == 0 {
+		handler.full = true
+	}
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package logging
+
+import "slices"
+
+// LoggerConfig is the configuration of a logger captured by [Logger.Snapshot]
+type LoggerConfig struct {
+	handlers    []Handler
+	minLevel    Level
+	hasMinLevel bool
+	emitElapsed bool
+
+	panicOnError   bool
+	skipCaller     bool
+	monotonicTime  bool
+	strictAttrs    bool
+	exitCode       int
+	callerLevel    Level
+	hasCallerLevel bool
+	emitGoroutine  bool
+	emitHostname   bool
+	emitPid        bool
+	errorHandler   func(handler Handler, err error)
+	redactedKeys   map[string]struct{}
+	redactors      []redactor
+	processors     []func(record *Record)
+}
+
+// Snapshot captures the handlers, minimum level and flags set on the logger, along with the
+// settings of the root logger that apply to the whole tree, so that they can be put back with
+// [Logger.Restore]:
+//
+//	defer logger.Restore(logger.Snapshot())
+func (logger *Logger) Snapshot() LoggerConfig {
+	var cfg LoggerConfig
+
+	logger.mutex.RLock()
+	cfg.handlers = slices.Clone(logger.handlers)
+	cfg.minLevel, cfg.hasMinLevel = logger.minLevel, logger.hasMinLevel
+	cfg.emitElapsed = logger.emitElapsed
+	logger.mutex.RUnlock()
+
+	root := logger.RootLogger()
+
+	root.mutex.RLock()
+	cfg.panicOnError = root.panicOnError
+	cfg.skipCaller = root.skipCaller
+	cfg.monotonicTime = root.monotonicTime
+	cfg.strictAttrs = root.strictAttrs
+	cfg.exitCode = root.exitCode
+	cfg.callerLevel, cfg.hasCallerLevel = root.callerLevel, root.hasCallerLevel
+	cfg.emitGoroutine = root.emitGoroutine
+	cfg.emitHostname, cfg.emitPid = root.emitHostname, root.emitPid
+	cfg.errorHandler = root.errorHandler
+	cfg.redactedKeys = root.redactedKeys
+	cfg.redactors = slices.Clip(root.redactors)
+	cfg.processors = slices.Clip(root.processors)
+	root.mutex.RUnlock()
+
+	return cfg
+}
+
+// Restore puts back the configuration captured by [Logger.Snapshot]. Handlers added since the
+// snapshot are detached and flushed like with [Logger.RemoveHandler].
+func (logger *Logger) Restore(cfg LoggerConfig) {
+	logger.mutex.Lock()
+	removed := slices.DeleteFunc(slices.Clone(logger.handlers), func(h Handler) bool {
+		return slices.ContainsFunc(cfg.handlers, func(c Handler) bool { return sameHandler(h, c) })
+	})
+
+	logger.handlers = slices.Clone(cfg.handlers)
+	logger.minLevel, logger.hasMinLevel = cfg.minLevel, cfg.hasMinLevel
+	logger.emitElapsed = cfg.emitElapsed
+	logger.mutex.Unlock()
+
+	root := logger.RootLogger()
+
+	root.mutex.Lock()
+	root.panicOnError = cfg.panicOnError
+	root.skipCaller = cfg.skipCaller
+	root.monotonicTime = cfg.monotonicTime
+	root.strictAttrs = cfg.strictAttrs
+	root.exitCode = cfg.exitCode
+	root.callerLevel, root.hasCallerLevel = cfg.callerLevel, cfg.hasCallerLevel
+	root.emitGoroutine = cfg.emitGoroutine
+	root.emitHostname, root.emitPid = cfg.emitHostname, cfg.emitPid
+	root.errorHandler = cfg.errorHandler
+	root.redactedKeys = cfg.redactedKeys
+	root.redactors = cfg.redactors
+	root.processors = cfg.processors
+	root.mutex.Unlock()
+
+	for _, handler := range removed {
+		if flusher, ok := handler.(Flusher); ok {
+			flusher.Flush()
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package logging
+
+import (
+	"io"
+	"runtime"
+	"time"
+)
+
+// SplitHandler pretty prints records below a threshold level to one writer and records at
+// or above the threshold to another, such as stdout and stderr respectively
+type SplitHandler struct {
+	threshold Level
+	low       PrettyHandler
+	high      PrettyHandler
+}
+
+func NewSplitHandler(lowWriter io.Writer, highWriter io.Writer, threshold Level, level Level) SplitHandler {
+	return SplitHandler{
+		threshold: threshold,
+		low:       NewPrettyHandler(lowWriter, level),
+		high:      NewPrettyHandler(highWriter, level),
+	}
+}
+
+// Implements [logging.LeveledHandler]
+func (handler SplitHandler) Level() Level {
+	return handler.low.Level()
+}
+
+// Implements [logging.Handler]
+func (handler SplitHandler) OnLoggerCreated(logger *Logger, timestamp time.Time, caller *runtime.Frame) {
+}
+
+// Implements [logging.Handler]
+func (handler SplitHandler) OnLoggerClosed(logger *Logger, timestamp time.Time, caller *runtime.Frame) error {
+	return nil
+}
+
+// Implements [logging.Handler]
+func (handler SplitHandler) HandleRecord(logger *Logger, record Record) error {
+	if record.Level < handler.threshold {
+		return handler.low.HandleRecord(logger, record)
+	}
+
+	return handler.high.HandleRecord(logger, record)
+}
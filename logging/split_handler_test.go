@@ -0,0 +1,43 @@
+package logging
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestSplitHandler(t *testing.T) {
+	var low, high bytes.Buffer
+	handler := NewSplitHandler(&low, &high, LevelWarn, LevelDebug)
+
+	logger := NewLogger()
+	for _, level := range []Level{LevelTrace, LevelDebug, LevelInfo, LevelWarn, LevelError} {
+		if err := handler.HandleRecord(logger, Record{Level: level, Message: "message-" + level.String()}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		output string
+		want   []string
+		absent []string
+	}{
+		{"low", low.String(), []string{"message-debug", "message-info"}, []string{"message-trace", "message-warn", "message-error"}},
+		{"high", high.String(), []string{"message-warn", "message-error"}, []string{"message-trace", "message-debug", "message-info"}},
+	}
+
+	for _, test := range tests {
+		for _, message := range test.want {
+			if !strings.Contains(test.output, message) {
+				t.Errorf("%s writer is missing %q: %q", test.name, message, test.output)
+			}
+		}
+
+		for _, message := range test.absent {
+			if strings.Contains(test.output, message) {
+				t.Errorf("%s writer unexpectedly contains %q: %q", test.name, message, test.output)
+			}
+		}
+	}
+}
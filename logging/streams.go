@@ -2,19 +2,125 @@ package logging
 
 import (
 	"bufio"
+	"bytes"
+	"errors"
 	"fmt"
 	"io"
+	"math"
+	"strings"
+	"sync"
 )
 
+// LevelSkip can be returned by the callback of [Logger.LogReaderFunc] to drop a line
+const LevelSkip Level = math.MinInt
+
 func (logger *Logger) LogReader(reader io.Reader, level Level, format string, args ...any) error {
+	return logger.LogReaderFunc(reader, func(line string) (Level, string, []any) {
+		return level, fmt.Sprintf(format, line), args
+	})
+}
+
+// LogReaderFunc logs each line read from reader using the level, message and attributes
+// returned by fn. Lines for which fn returns [LevelSkip] are not logged.
+func (logger *Logger) LogReaderFunc(reader io.Reader, fn func(line string) (Level, string, []any)) error {
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		level, message, args := fn(scanner.Text())
+		if level == LevelSkip {
+			continue
+		}
+
+		logger.Log(level, message, args...)
+	}
+
+	return scanner.Err()
+}
+
+// LogReaderSource is like [Logger.LogReader] for output that does not come from Go code,
+// such as the output of a subprocess. Records carry a "source" attribute with source
+// instead of the caller, which would only be the code reading the output.
+func (logger *Logger) LogReaderSource(reader io.Reader, source string, level Level, format string, args ...any) error {
+	return logger.LogReaderSourceFunc(reader, source, func(line string) (Level, string, []any) {
+		return level, fmt.Sprintf(format, line), args
+	})
+}
+
+// LogReaderSourceFunc is like [Logger.LogReaderFunc] with the source attribute and without
+// the caller of [Logger.LogReaderSource]
+func (logger *Logger) LogReaderSourceFunc(reader io.Reader, source string, fn func(line string) (Level, string, []any)) error {
 	scanner := bufio.NewScanner(reader)
 	for scanner.Scan() {
-		if err := scanner.Err(); err != nil {
-			return err
+		level, message, args := fn(scanner.Text())
+		if level == LevelSkip {
+			continue
+		}
+
+		// Records passed to LogBatch keep the caller they were given
+		record := Record{
+			Level:      level,
+			Message:    message,
+			Attributes: append(argsToAttrs(args), Attribute{Key: "source", Value: source}),
+		}
+
+		logger.LogBatch([]Record{record})
+	}
+
+	return scanner.Err()
+}
+
+type logWriter struct {
+	mutex sync.Mutex
+
+	logger *Logger
+	level  Level
+	buf    []byte
+}
+
+// Writer returns a writer that logs each line written to it at level. Partial lines are
+// buffered until they are completed by a later write or the writer is closed.
+func (logger *Logger) Writer(level Level) io.WriteCloser {
+	return &logWriter{logger: logger, level: level}
+}
+
+// Implements [io.Writer]
+func (writer *logWriter) Write(p []byte) (int, error) {
+	writer.mutex.Lock()
+	defer writer.mutex.Unlock()
+
+	writer.buf = append(writer.buf, p...)
+
+	errs := make([]error, 0)
+	start := 0
+	for {
+		i := bytes.IndexByte(writer.buf[start:], '\n')
+		if i == -1 {
+			break
 		}
 
-		logger.Log(level, fmt.Sprintf(format, scanner.Text()), args...)
+		line := strings.TrimSuffix(string(writer.buf[start:start+i]), "\r")
+		start += i + 1
+
+		errs = append(errs, writer.logger.Log(writer.level, line))
 	}
 
-	return nil
+	// Move the partial line to the start of the buffer so that it does not grow unbounded
+	n := copy(writer.buf, writer.buf[start:])
+	writer.buf = writer.buf[:n]
+
+	return len(p), errors.Join(errs...)
+}
+
+// Implements [io.Closer]
+func (writer *logWriter) Close() error {
+	writer.mutex.Lock()
+	defer writer.mutex.Unlock()
+
+	if len(writer.buf) == 0 {
+		return nil
+	}
+
+	line := strings.TrimSuffix(string(writer.buf), "\r")
+	writer.buf = nil
+
+	return writer.logger.Log(writer.level, line)
 }
@@ -0,0 +1,49 @@
+package logging
+
+import (
+	"io"
+	"os"
+	"sync"
+)
+
+// SyncWriter serializes writes to the wrapped writer, so that the bytes of one call to Write
+// are never interleaved with those of another
+type SyncWriter struct {
+	mutex  sync.Mutex
+	writer io.Writer
+}
+
+func NewSyncWriter(writer io.Writer) *SyncWriter {
+	return &SyncWriter{writer: writer}
+}
+
+// Implements [io.Writer]
+func (w *SyncWriter) Write(p []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	return w.writer.Write(p)
+}
+
+// Unwrap returns the wrapped writer, so that handlers can still detect terminals
+func (w *SyncWriter) Unwrap() io.Writer {
+	return w.writer
+}
+
+// fileMutexes holds a mutex per *os.File, which are commonly shared between handlers, such as
+// a pretty and a JSON handler both writing to stdout
+var fileMutexes sync.Map
+
+// writeRecord writes the rendered bytes of a record to writer in a single call. Writes to the
+// same *os.File are serialized across all handlers, other writers are expected to be safe
+// for concurrent use or wrapped in a [SyncWriter].
+func writeRecord(writer io.Writer, p []byte) error {
+	if file, ok := writer.(*os.File); ok {
+		mutex, _ := fileMutexes.LoadOrStore(file, &sync.Mutex{})
+		mutex.(*sync.Mutex).Lock()
+		defer mutex.(*sync.Mutex).Unlock()
+	}
+
+	_, err := writer.Write(p)
+	return err
+}
@@ -0,0 +1,78 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// writeConcurrently logs large records from several goroutines to a JSON and a compact
+// handler sharing writer, and checks that every line written is a complete record. Run with
+// -race to also check the serialization itself.
+func writeConcurrently(t *testing.T, writer io.Writer, read func() []byte) {
+	t.Helper()
+
+	payload := strings.Repeat("x", 64<<10)
+
+	logger := NewLogger()
+	logger.AddHandler(NewJsonHandler(writer, LevelTrace))
+	logger.AddHandler(NewCompactHandler(writer, LevelTrace).WithColor(false))
+
+	const goroutines, records = 8, 20
+
+	var wg sync.WaitGroup
+	for range goroutines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for range records {
+				logger.Info("message", "payload", payload)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	lines := strings.Split(strings.TrimSuffix(string(read()), "\n"), "\n")
+	if len(lines) != 2*goroutines*records {
+		t.Fatalf("got %d lines, want %d", len(lines), 2*goroutines*records)
+	}
+
+	for i, line := range lines {
+		if strings.HasPrefix(line, "{") {
+			if !json.Valid([]byte(line)) || !strings.Contains(line, payload) {
+				t.Fatalf("line %d is not a complete JSON record", i)
+			}
+		} else if !strings.HasSuffix(line, " payload="+payload) {
+			t.Fatalf("line %d is not a complete compact record", i)
+		}
+	}
+}
+
+func TestConcurrentWritesToFile(t *testing.T) {
+	file, err := os.Create(filepath.Join(t.TempDir(), "log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	writeConcurrently(t, file, func() []byte {
+		data, err := os.ReadFile(file.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return data
+	})
+}
+
+func TestConcurrentWritesToSyncWriter(t *testing.T) {
+	var buf bytes.Buffer
+	writeConcurrently(t, NewSyncWriter(&buf), buf.Bytes)
+}
@@ -0,0 +1,272 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type SyslogFacility int
+
+const (
+	SyslogFacility_Kern SyslogFacility = iota
+	SyslogFacility_User
+	SyslogFacility_Mail
+	SyslogFacility_Daemon
+	SyslogFacility_Auth
+	SyslogFacility_Syslog
+	SyslogFacility_Lpr
+	SyslogFacility_News
+	SyslogFacility_Uucp
+	SyslogFacility_Cron
+	SyslogFacility_AuthPriv
+	SyslogFacility_Ftp
+	SyslogFacility_Ntp
+	SyslogFacility_Audit
+	SyslogFacility_Alert
+	SyslogFacility_Clock
+	SyslogFacility_Local0
+	SyslogFacility_Local1
+	SyslogFacility_Local2
+	SyslogFacility_Local3
+	SyslogFacility_Local4
+	SyslogFacility_Local5
+	SyslogFacility_Local6
+	SyslogFacility_Local7
+)
+
+// Private enterprise number reserved for documentation (RFC 5612), used to qualify the
+// structured data IDs
+const syslogEnterpriseId = "32473"
+
+// SyslogHandler sends records to a syslog server formatted as RFC 5424 messages. Attributes
+// and the logger tree are sent as structured data.
+type SyslogHandler struct {
+	mutex sync.Mutex
+
+	network  string
+	address  string
+	appName  string
+	facility SyslogFacility
+	level    Level
+	hostname string
+
+	conn net.Conn
+}
+
+// NewSyslogHandler creates a handler sending to address over network, which must be "udp"
+// or "tcp". The connection is established on the first record and re-established if a
+// write fails.
+func NewSyslogHandler(network string, address string, appName string, facility SyslogFacility, level Level) *SyslogHandler {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "-"
+	}
+
+	return &SyslogHandler{
+		network:  network,
+		address:  address,
+		appName:  appName,
+		facility: facility,
+		level:    level,
+		hostname: hostname,
+	}
+}
+
+// Implements [io.Closer]
+func (handler *SyslogHandler) Close() error {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	if handler.conn == nil {
+		return nil
+	}
+
+	err := handler.conn.Close()
+	handler.conn = nil
+
+	return err
+}
+
+// Implements [logging.ContextCloser]. If a write to an unreachable server is still blocked
+// when ctx is done, CloseContext returns and the connection is closed once the write
+// gives up.
+func (handler *SyslogHandler) CloseContext(ctx context.Context) error {
+	done := make(chan error, 1)
+	go func() { done <- handler.Close() }()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Implements [logging.LeveledHandler]
+func (handler *SyslogHandler) Level() Level {
+	return handler.level
+}
+
+// Implements [logging.Handler]
+func (handler *SyslogHandler) OnLoggerCreated(logger *Logger, timestamp time.Time, caller *runtime.Frame) {
+}
+
+// Implements [logging.Handler]
+func (handler *SyslogHandler) OnLoggerClosed(logger *Logger, timestamp time.Time, caller *runtime.Frame) error {
+	return nil
+}
+
+// Implements [logging.Handler]
+func (handler *SyslogHandler) HandleRecord(logger *Logger, record Record) error {
+	if record.Level < handler.level {
+		return nil
+	}
+
+	msg := handler.format(logger, record)
+	if handler.network == "tcp" {
+		// Octet counting framing (RFC 6587 section 3.4.1)
+		msg = strconv.Itoa(len(msg)) + " " + msg
+	}
+
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	err := handler.write(msg)
+	if err != nil && handler.conn != nil {
+		// Retry once on a fresh connection in case the server restarted
+		handler.conn.Close()
+		handler.conn = nil
+
+		err = handler.write(msg)
+	}
+
+	return err
+}
+
+func (handler *SyslogHandler) write(msg string) error {
+	if handler.conn == nil {
+		conn, err := net.Dial(handler.network, handler.address)
+		if err != nil {
+			return err
+		}
+
+		handler.conn = conn
+	}
+
+	_, err := handler.conn.Write([]byte(msg))
+	return err
+}
+
+func (handler *SyslogHandler) format(logger *Logger, record Record) string {
+	var msg strings.Builder
+
+	priority := int(handler.facility)*8 + syslogSeverity(record.Level)
+	fmt.Fprintf(&msg, "<%d>1 %s %s %s %d - ",
+		priority,
+		record.Time.UTC().Format("2006-01-02T15:04:05.000000Z07:00"),
+		handler.hostname,
+		syslogHeaderField(handler.appName, 48),
+		os.Getpid(),
+	)
+
+	msg.WriteString("[logger@" + syslogEnterpriseId)
+	writeSyslogParam(&msg, "id", logger.id.String())
+	writeSyslogParam(&msg, "root", logger.RootLogger().id.String())
+	if record.Caller != nil {
+		writeSyslogParam(&msg, "file", record.Caller.File)
+		writeSyslogParam(&msg, "line", strconv.Itoa(record.Caller.Line))
+	}
+	msg.WriteString("]")
+
+	if attrs := resolveAttrs(record.Attributes); len(attrs) > 0 {
+		msg.WriteString("[attrs@" + syslogEnterpriseId)
+		writeSyslogAttrs(&msg, "", attrs)
+		msg.WriteString("]")
+	}
+
+	msg.WriteString(" ")
+	msg.WriteString(record.Message)
+
+	return msg.String()
+}
+
+func writeSyslogAttrs(msg *strings.Builder, prefix string, attrs []Attribute) {
+	for _, attr := range attrs {
+		switch v := attr.Value.(type) {
+		case []Attribute:
+			writeSyslogAttrs(msg, prefix+attr.Key+".", v)
+		case error:
+			writeSyslogParam(msg, prefix+attr.Key, v.Error())
+		default:
+			writeSyslogParam(msg, prefix+attr.Key, fmt.Sprintf("%v", v))
+		}
+	}
+}
+
+// writeSyslogParam writes an SD-PARAM, escaping the characters reserved in PARAM-VALUE
+func writeSyslogParam(msg *strings.Builder, name string, value string) {
+	msg.WriteString(" ")
+	msg.WriteString(syslogSdName(name))
+	msg.WriteString(`="`)
+
+	for _, r := range value {
+		if r == '"' || r == '\\' || r == ']' {
+			msg.WriteRune('\\')
+		}
+
+		msg.WriteRune(r)
+	}
+
+	msg.WriteString(`"`)
+}
+
+// syslogSdName replaces the characters that are not allowed in an SD-NAME and truncates it
+// to the maximum of 32 characters
+func syslogSdName(name string) string {
+	var sdName strings.Builder
+
+	for _, r := range name {
+		if r < 33 || r > 126 || r == '=' || r == ']' || r == '"' {
+			sdName.WriteRune('_')
+		} else {
+			sdName.WriteRune(r)
+		}
+
+		if sdName.Len() == 32 {
+			break
+		}
+	}
+
+	if sdName.Len() == 0 {
+		return "_"
+	}
+
+	return sdName.String()
+}
+
+// syslogHeaderField returns the NILVALUE for empty fields and truncates fields to maxLen
+func syslogHeaderField(value string, maxLen int) string {
+	value = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return '_'
+		}
+		return r
+	}, value)
+
+	if value == "" {
+		return "-"
+	}
+
+	if len(value) > maxLen {
+		return value[:maxLen]
+	}
+
+	return value
+}
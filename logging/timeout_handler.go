@@ -0,0 +1,96 @@
+package logging
+
+import (
+	"errors"
+	"runtime"
+	"sync/atomic"
+	"time"
+)
+
+var ErrHandlerTimeout = errors.New("handler timed out")
+
+// TimeoutHandler abandons calls to the wrapped handler that take longer than a timeout.
+// Calls are made one at a time on a separate goroutine; while an abandoned call is still
+// running, new calls wait for it up to the same timeout instead of starting another
+// goroutine, so a wedged handler holds on to at most one goroutine.
+type TimeoutHandler struct {
+	inner   Handler
+	timeout time.Duration
+
+	slot   chan struct{}
+	stalls atomic.Uint64
+}
+
+func NewTimeoutHandler(inner Handler, timeout time.Duration) *TimeoutHandler {
+	return &TimeoutHandler{inner: inner, timeout: timeout, slot: make(chan struct{}, 1)}
+}
+
+// Stalls returns the number of calls that timed out
+func (handler *TimeoutHandler) Stalls() uint64 {
+	return handler.stalls.Load()
+}
+
+// run calls fn on its own goroutine, returning [ErrHandlerTimeout] if it does not finish
+// within the timeout
+func (handler *TimeoutHandler) run(fn func() error) error {
+	timer := time.NewTimer(handler.timeout)
+	defer timer.Stop()
+
+	select {
+	case handler.slot <- struct{}{}:
+	case <-timer.C:
+		handler.stalls.Add(1)
+		return ErrHandlerTimeout
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		defer func() { <-handler.slot }()
+		done <- fn()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+		handler.stalls.Add(1)
+		return ErrHandlerTimeout
+	}
+}
+
+// Implements [logging.Flusher]
+func (handler *TimeoutHandler) Flush() error {
+	flusher, ok := handler.inner.(Flusher)
+	if !ok {
+		return nil
+	}
+
+	return handler.run(flusher.Flush)
+}
+
+// Implements [logging.LeveledHandler]
+func (handler *TimeoutHandler) Level() Level {
+	return handlerLevel(handler.inner)
+}
+
+// Implements [logging.Handler]
+func (handler *TimeoutHandler) OnLoggerCreated(logger *Logger, timestamp time.Time, caller *runtime.Frame) {
+	handler.run(func() error {
+		handler.inner.OnLoggerCreated(logger, timestamp, caller)
+		return nil
+	})
+}
+
+// Implements [logging.Handler]
+func (handler *TimeoutHandler) OnLoggerClosed(logger *Logger, timestamp time.Time, caller *runtime.Frame) error {
+	return handler.run(func() error {
+		return handler.inner.OnLoggerClosed(logger, timestamp, caller)
+	})
+}
+
+// Implements [logging.Handler]
+func (handler *TimeoutHandler) HandleRecord(logger *Logger, record Record) error {
+	return handler.run(func() error {
+		return handler.inner.HandleRecord(logger, record)
+	})
+}
@@ -0,0 +1,48 @@
+package logging
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+var errWrite = errors.New("write failed")
+
+// failingWriter fails every write, like a broken pipe
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errWrite
+}
+
+func TestHandlersReturnWriterErrors(t *testing.T) {
+	handlers := map[string]Handler{
+		"json":    NewJsonHandler(failingWriter{}, LevelTrace),
+		"pretty":  NewPrettyHandler(failingWriter{}, LevelTrace),
+		"compact": NewCompactHandler(failingWriter{}, LevelTrace),
+	}
+
+	for name, handler := range handlers {
+		t.Run(name, func(t *testing.T) {
+			logger := NewLogger()
+
+			if err := handler.HandleRecord(logger, Record{Time: time.Now(), Level: LevelInfo, Message: "message"}); !errors.Is(err, errWrite) {
+				t.Errorf("HandleRecord returned %v, want %v", err, errWrite)
+			}
+
+			logger.AddHandler(handler)
+			if err := logger.Info("message"); !errors.Is(err, errWrite) {
+				t.Errorf("Info returned %v, want %v", err, errWrite)
+			}
+		})
+	}
+}
+
+func TestJsonHandlerOnLoggerClosedReturnsWriterError(t *testing.T) {
+	logger := NewLogger()
+	logger.AddHandler(NewJsonHandler(failingWriter{}, LevelTrace))
+
+	if err := logger.Close(); !errors.Is(err, errWrite) {
+		t.Errorf("Close returned %v, want %v", err, errWrite)
+	}
+}
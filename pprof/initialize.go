@@ -1,17 +1,126 @@
 package pprof
 
 import (
+	"context"
+	"crypto/subtle"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
-	_ "net/http/pprof"
+	"net/http/pprof"
 	"runtime"
+	"sync"
 )
 
-func init() {
-	go func() {
-		fmt.Println(http.ListenAndServe("localhost:6060", nil))
-	}()
+const DefaultAddress = "localhost:6060"
+
+var ErrAlreadyStarted = errors.New("pprof server already started")
+
+var (
+	mutex  sync.Mutex
+	server *http.Server
+)
 
+// Middleware wraps the pprof handlers, for example to require authentication
+type Middleware func(next http.Handler) http.Handler
+
+// Importing the package only enables the mutex and block profiles. The endpoints are not
+// served until [Start] is called, so that they are never exposed without the middleware.
+func init() {
 	runtime.SetMutexProfileFraction(16)
 	runtime.SetBlockProfileRate(16)
 }
+
+func newMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	return mux
+}
+
+// Start serves the pprof endpoints on address, wrapped in middleware if it is not nil. A
+// running server must be stopped with [Stop] before starting a new one.
+func Start(address string, middleware Middleware) error {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if server != nil {
+		return ErrAlreadyStarted
+	}
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+
+	var handler http.Handler = newMux()
+	if middleware != nil {
+		handler = middleware(handler)
+	}
+
+	server = &http.Server{Handler: handler}
+
+	go func(server *http.Server) {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fmt.Println(err)
+		}
+	}(server)
+
+	return nil
+}
+
+// Stop gracefully shuts down the running server, waiting for active requests until ctx is
+// done. It does nothing if no server is running.
+func Stop(ctx context.Context) error {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if server == nil {
+		return nil
+	}
+
+	err := server.Shutdown(ctx)
+	server = nil
+
+	return err
+}
+
+// BearerToken requires requests to send token in an "Authorization: Bearer" header
+func BearerToken(token string) Middleware {
+	expected := []byte("Bearer " + token)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// BasicAuth requires requests to authenticate with username and password using HTTP basic
+// authentication
+func BasicAuth(username string, password string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, pass, ok := r.BasicAuth()
+			userOk := subtle.ConstantTimeCompare([]byte(user), []byte(username)) == 1
+			passOk := subtle.ConstantTimeCompare([]byte(pass), []byte(password)) == 1
+
+			if !ok || !userOk || !passOk {
+				w.Header().Set("WWW-Authenticate", `Basic realm="pprof"`)
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}